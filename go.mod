@@ -3,8 +3,8 @@ module tls_tests
 go 1.16
 
 require (
-	github.com/go-echarts/go-echarts/v2 v2.2.4 // indirect
+	github.com/go-echarts/go-echarts/v2 v2.2.4
 	github.com/klauspost/cpuid v0.0.0-20180405133222-e7e905edc00e // indirect
-	github.com/valyala/fasthttp v1.34.0 // indirect
-	gonum.org/v1/plot v0.10.0 // indirect
+	github.com/valyala/fasthttp v1.34.0
+	gonum.org/v1/plot v0.10.0
 )
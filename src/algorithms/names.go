@@ -0,0 +1,148 @@
+package algorithms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/liboqs_sig"
+	"crypto/rsa"
+	"crypto/tls"
+	"errors"
+)
+
+// Returns the NIST security level of an algorithm of the default registry
+func SecurityLevel(k string) (level int) {
+	algo, err := Default.Get(k)
+	if err != nil {
+		panic("Error when recovering NIST security level number.")
+	}
+	return algo.Level
+}
+
+// NIST level of the Intermediate CA issuing the certificates of the combinations of algorithms in the same level
+const DefaultCALevel = 3
+
+// Returns the NIST level of the Intermediate CA issuing the certificates of a combination of algorithms. The
+// combinations in the same level use the default level, and the cross level ones the higher level of their
+// components, so that the chain is not weaker than any of them.
+func CALevel(kex, auth string) int {
+	kexLevel, authLevel := SecurityLevel(kex), SecurityLevel(auth)
+	if kexLevel == authLevel {
+		return DefaultCALevel
+	}
+	if kexLevel > authLevel {
+		return kexLevel
+	}
+	return authLevel
+}
+
+// Returns the class of an algorithm of the default registry
+func ClassOf(k string) Class {
+	algo, err := Default.Get(k)
+	if err != nil {
+		panic(err)
+	}
+	return algo.Class
+}
+
+func NameToCurveID(name string) (tls.CurveID, error) {
+	algo, err := Default.Get(name)
+	if err != nil || algo.Kind != KEM {
+		return 0, errors.New("Error: key exchange algorithm not found")
+	}
+	return algo.CurveID, nil
+}
+
+// Returns the elliptic.Curve or RSAKeySize of a classic signature algorithm
+func NameToClassicSigAlgo(name string) (interface{}, error) {
+	algo, err := Default.Get(name)
+	if err != nil || algo.Kind != Signature || algo.Class != Classic {
+		return nil, errors.New("Error: classic signature algorithm not found")
+	}
+	return algo.ClassicSig, nil
+}
+
+func NameToSigID(name string) (liboqs_sig.ID, error) {
+	algo, err := Default.Get(name)
+	if err != nil || algo.Kind != Signature || algo.Class == Classic {
+		return 0, errors.New("Error: signature algorithm not found")
+	}
+	return algo.SigID, nil
+}
+
+func CurveIDToName(cID tls.CurveID) (name string, e error) {
+	for _, algo := range Default.Algorithms {
+		if algo.Kind == KEM && algo.CurveID == cID {
+			return algo.Name, nil
+		}
+	}
+	return "0", errors.New("Error: key exchange algorithm not found")
+}
+
+func SigIDToName(sigID interface{}) (name string, e error) {
+	lID := sigID.(liboqs_sig.ID)
+
+	for _, algo := range Default.Algorithms {
+		if algo.Kind == Signature && algo.Class != Classic && algo.SigID == lID {
+			return algo.Name, nil
+		}
+	}
+
+	return "0", errors.New("Error: signature algorithm not found")
+}
+
+// Returns the name of the classic signature algorithm of an ECDSA or RSA private key
+func ClassicSigToName(priv interface{}) (name string, e error) {
+	for _, algo := range Default.Algorithms {
+		if algo.Kind != Signature || algo.Class != Classic {
+			continue
+		}
+		switch k := priv.(type) {
+		case *ecdsa.PrivateKey:
+			if curve, ok := algo.ClassicSig.(elliptic.Curve); ok && curve == k.Curve {
+				return algo.Name, nil
+			}
+		case *rsa.PrivateKey:
+			if bits, ok := algo.ClassicSig.(RSAKeySize); ok && int(bits) == k.N.BitLen() {
+				return algo.Name, nil
+			}
+		}
+	}
+
+	return "0", errors.New("Error: classic signature algorithm not found")
+}
+
+// Returns the Classic McEliece algorithm used for the KEMTLS authentication in the security level and class
+// (hybrid or pure) of the key exchange algorithm k
+func ClassicMcEliece(k string) string {
+	algo, err := Default.Find(KEM, ClassOf(k), "Classic_McEliece", SecurityLevel(k))
+	if err != nil {
+		panic(err)
+	}
+	return algo.Name
+}
+
+// Selects the algorithms of the classes to be tested from the default registry. Empty selections are replaced by
+// the default ones of the classic TLS mode, if the class is Classic, or of the post-quantum modes otherwise.
+func SelectTests(kexSelection, authSelection string, classes ...Class) (keysKEX, keysAuth []string, err error) {
+	defaultKEX, defaultAuth := DefaultKEXSelection, DefaultSignatureSelection
+	if containsClass(classes, Classic) {
+		defaultKEX, defaultAuth = DefaultClassicKEXSelection, DefaultClassicAuthSelection
+	}
+
+	if kexSelection == "" {
+		kexSelection = defaultKEX
+	}
+	if authSelection == "" {
+		authSelection = defaultAuth
+	}
+
+	keysKEX, err = Default.Select(kexSelection, KEM, classes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	keysAuth, err = Default.Select(authSelection, Signature, classes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keysKEX, keysAuth, nil
+}
@@ -0,0 +1,349 @@
+// Package algorithms is the registry of the key exchange and signature algorithms that can be tested.
+package algorithms
+
+import (
+	"crypto/elliptic"
+	"crypto/kem"
+	"crypto/liboqs_sig"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind of the algorithms in the registry
+type Kind int
+
+const (
+	// Key exchange, also used for authentication in KEMTLS
+	KEM Kind = iota
+	Signature
+)
+
+// Class of the algorithms in the registry
+type Class int
+
+const (
+	Hybrid Class = iota
+	Pure
+	Classic
+)
+
+// RSA key size of a classic signature algorithm
+type RSAKeySize int
+
+// Description of an algorithm of the registry
+type Info struct {
+	Name      string
+	Kind      Kind
+	Class     Class
+	Family    string // Post-quantum family, or the classic algorithm of classic ones
+	Classical string // Classical component (P256, P384, P521, X25519 or RSA), empty if pure post-quantum
+	Level     int    // NIST security level
+
+	CurveID    tls.CurveID   // KEMs
+	SigID      liboqs_sig.ID // Hybrid and pure post-quantum signatures
+	ClassicSig interface{}   // Classic signatures: elliptic.Curve or RSAKeySize
+
+	// KEM sizes, as reported by liboqs
+	PublicKeySize  int
+	CiphertextSize int
+}
+
+type Registry struct {
+	Algorithms []*Info
+	byName     map[string]*Info
+}
+
+// Algorithms known by the registry, ordered by family. The NIST level and sizes of the KEMs, and the
+// signature IDs and classical components of the signatures are retrieved from liboqs.
+var (
+	registryKEMs = []struct {
+		name, family string
+		curveID      tls.CurveID
+	}{
+		{"P256_Kyber512", "Kyber", tls.P256_Kyber512}, {"P384_Kyber768", "Kyber", tls.P384_Kyber768}, {"P521_Kyber1024", "Kyber", tls.P521_Kyber1024},
+		{"P256_HQC_128", "HQC", tls.P256_HQC_128}, {"P384_HQC_192", "HQC", tls.P384_HQC_192}, {"P521_HQC_256", "HQC", tls.P521_HQC_256},
+		{"P256_BIKE_L1", "BIKE", tls.P256_BIKE_L1}, {"P384_BIKE_L3", "BIKE", tls.P384_BIKE_L3}, {"P521_BIKE_L5", "BIKE", tls.P521_BIKE_L5},
+		{"P256_Classic_McEliece_348864", "Classic_McEliece", tls.P256_Classic_McEliece_348864},
+		{"P384_Classic_McEliece_460896", "Classic_McEliece", tls.P384_Classic_McEliece_460896},
+		{"P521_Classic_McEliece_6688128", "Classic_McEliece", tls.P521_Classic_McEliece_6688128},
+	}
+
+	registrySignatures = []struct {
+		name, family string
+	}{
+		{"P256_Dilithium2", "Dilithium"}, {"P384_Dilithium3", "Dilithium"}, {"P521_Dilithium5", "Dilithium"},
+		{"P256_Falcon512", "Falcon"}, {"P521_Falcon1024", "Falcon"},
+	}
+
+	// Pure post-quantum algorithms, without the classical component of the hybrid ones. Their NIST level is used
+	// if liboqs does not report it.
+	registryPureKEMs = []struct {
+		name, family string
+		curveID      tls.CurveID
+		level        int
+	}{
+		{"Kyber512", "Kyber", tls.Kyber512, 1}, {"Kyber768", "Kyber", tls.Kyber768, 3}, {"Kyber1024", "Kyber", tls.Kyber1024, 5},
+		{"HQC_128", "HQC", tls.HQC_128, 1}, {"HQC_192", "HQC", tls.HQC_192, 3}, {"HQC_256", "HQC", tls.HQC_256, 5},
+		{"BIKE_L1", "BIKE", tls.BIKE_L1, 1}, {"BIKE_L3", "BIKE", tls.BIKE_L3, 3}, {"BIKE_L5", "BIKE", tls.BIKE_L5, 5},
+		{"Classic_McEliece_348864", "Classic_McEliece", tls.Classic_McEliece_348864, 1},
+		{"Classic_McEliece_460896", "Classic_McEliece", tls.Classic_McEliece_460896, 3},
+		{"Classic_McEliece_6688128", "Classic_McEliece", tls.Classic_McEliece_6688128, 5},
+	}
+
+	registryPureSignatures = []struct {
+		name, family string
+		level        int
+	}{
+		{"Dilithium2", "Dilithium", 1}, {"Dilithium3", "Dilithium", 3}, {"Dilithium5", "Dilithium", 5},
+		{"Falcon512", "Falcon", 1}, {"Falcon1024", "Falcon", 5},
+	}
+
+	registryClassic = []*Info{
+		{Name: "X25519", Kind: KEM, Family: "ECDH", Classical: "X25519", Level: 1, CurveID: tls.X25519},
+		{Name: "P256", Kind: KEM, Family: "ECDH", Classical: "P256", Level: 1, CurveID: tls.CurveP256},
+		{Name: "P384", Kind: KEM, Family: "ECDH", Classical: "P384", Level: 3, CurveID: tls.CurveP384},
+		{Name: "P521", Kind: KEM, Family: "ECDH", Classical: "P521", Level: 5, CurveID: tls.CurveP521},
+		{Name: "ECDSA_P256", Kind: Signature, Family: "ECDSA", Classical: "P256", Level: 1, ClassicSig: elliptic.P256()},
+		{Name: "ECDSA_P384", Kind: Signature, Family: "ECDSA", Classical: "P384", Level: 3, ClassicSig: elliptic.P384()},
+		{Name: "ECDSA_P521", Kind: Signature, Family: "ECDSA", Classical: "P521", Level: 5, ClassicSig: elliptic.P521()},
+		// RSA_3072 has about 128 bits of classical security, the same as AES-128, so it is in Level 1 like
+		// ECDSA_P256. A Level 3 RSA key would need 7680 bits. RSA_2048, at about 112 bits, is below Level 1 and is
+		// placed there as the closest level.
+		{Name: "RSA_2048", Kind: Signature, Family: "RSA", Classical: "RSA", Level: 1, ClassicSig: RSAKeySize(2048)},
+		{Name: "RSA_3072", Kind: Signature, Family: "RSA", Classical: "RSA", Level: 1, ClassicSig: RSAKeySize(3072)},
+	}
+)
+
+// Default selections of the algorithms to be tested
+const (
+	DefaultKEXSelection           = "HQC,BIKE"
+	DefaultSignatureSelection     = "Dilithium"
+	DefaultClassicKEXSelection    = "ECDH"
+	DefaultClassicAuthSelection   = "ECDSA,RSA_2048"
+	DefaultBenchmarkKEMSelection  = "P521_HQC_256"
+	DefaultBenchmarkSigsSelection = "Dilithium,Falcon"
+)
+
+// Registry of every algorithm available in the linked liboqs
+var Default = New()
+
+func New() *Registry {
+	r := &Registry{byName: make(map[string]*Info)}
+
+	for _, k := range registryKEMs {
+		a := &Info{Name: k.name, Kind: KEM, Class: Hybrid, Family: k.family, CurveID: k.curveID}
+		a.Classical = classicalFromName(k.name)
+
+		if details, err := kem.GetKemDetails(kem.ID(k.curveID)); err == nil {
+			a.Level = details.ClaimedNISTLevel
+			a.PublicKeySize = details.PublicKeySize
+			a.CiphertextSize = details.CiphertextSize
+		}
+		if a.Level == 0 {
+			a.Level = levelFromClassical(a.Classical)
+		}
+
+		r.add(a)
+	}
+
+	for _, s := range registrySignatures {
+		sigID, err := liboqs_sig.NameToSigID(s.name)
+		if err != nil {
+			// Not available in the linked liboqs
+			continue
+		}
+
+		a := &Info{Name: s.name, Kind: Signature, Class: Hybrid, Family: s.family, SigID: sigID}
+		a.Classical = classicalFromName(s.name)
+
+		if curve, _ := liboqs_sig.ClassicFromSig(sigID); curve != nil {
+			a.Classical = strings.ReplaceAll(curve.Params().Name, "-", "")
+		}
+		a.Level = levelFromClassical(a.Classical)
+
+		r.add(a)
+	}
+
+	for _, k := range registryPureKEMs {
+		a := &Info{Name: k.name, Kind: KEM, Class: Pure, Family: k.family, Level: k.level, CurveID: k.curveID}
+
+		if details, err := kem.GetKemDetails(kem.ID(k.curveID)); err == nil {
+			if details.ClaimedNISTLevel != 0 {
+				a.Level = details.ClaimedNISTLevel
+			}
+			a.PublicKeySize = details.PublicKeySize
+			a.CiphertextSize = details.CiphertextSize
+		}
+
+		r.add(a)
+	}
+
+	for _, s := range registryPureSignatures {
+		sigID, err := liboqs_sig.NameToSigID(s.name)
+		if err != nil {
+			continue
+		}
+
+		r.add(&Info{Name: s.name, Kind: Signature, Class: Pure, Family: s.family, Level: s.level, SigID: sigID})
+	}
+
+	for _, a := range registryClassic {
+		a.Class = Classic
+		r.add(a)
+	}
+
+	return r
+}
+
+func (r *Registry) add(a *Info) {
+	r.Algorithms = append(r.Algorithms, a)
+	r.byName[a.Name] = a
+}
+
+func (r *Registry) Get(name string) (*Info, error) {
+	a, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("Error: algorithm %s not found", name)
+	}
+	return a, nil
+}
+
+// Returns the algorithm of the family in the security level, or the one in the closest lower level if the
+// family does not have an algorithm in it
+func (r *Registry) Find(kind Kind, class Class, family string, level int) (*Info, error) {
+	var found *Info
+
+	for _, a := range r.Algorithms {
+		if a.Kind != kind || a.Class != class || !strings.EqualFold(a.Family, family) || a.Level > level {
+			continue
+		}
+		if found == nil || a.Level > found.Level {
+			found = a
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("Error: no %s algorithm in level %d", family, level)
+	}
+	return found, nil
+}
+
+// Selects algorithms of the kind and classes by a comma separated list of algorithm names, families (e.g. HQC),
+// levels (L1, L3 or L5) or "all". The selected algorithms are ordered by level.
+func (r *Registry) Select(selection string, kind Kind, classes ...Class) ([]string, error) {
+	selected := make(map[string]bool)
+
+	for _, token := range strings.Split(selection, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		matched := false
+		for _, a := range r.Algorithms {
+			if a.Kind != kind || !containsClass(classes, a.Class) {
+				continue
+			}
+			if strings.EqualFold(token, "all") || token == a.Name || strings.EqualFold(token, a.Family) ||
+				strings.EqualFold(token, fmt.Sprintf("L%d", a.Level)) {
+				selected[a.Name] = true
+				matched = true
+			}
+		}
+
+		if !matched {
+			return nil, fmt.Errorf("Error: %s does not match any algorithm", token)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.New("Error: empty algorithm selection")
+	}
+
+	var names []string
+	for _, a := range r.Algorithms {
+		if selected[a.Name] {
+			names = append(names, a.Name)
+		}
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return r.byName[names[i]].Level < r.byName[names[j]].Level
+	})
+
+	return names, nil
+}
+
+// Returns the names of the algorithms of the kind and class in the security level
+func (r *Registry) NamesInLevel(kind Kind, class Class, level int) []string {
+	var names []string
+	for _, a := range r.Algorithms {
+		if a.Kind == kind && a.Class == class && a.Level == level {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+func containsClass(classes []Class, c Class) bool {
+	for _, class := range classes {
+		if class == c {
+			return true
+		}
+	}
+	return false
+}
+
+func classicalFromName(name string) string {
+	for _, c := range []string{"P256", "P384", "P521"} {
+		if strings.HasPrefix(name, c+"_") {
+			return c
+		}
+	}
+	return ""
+}
+
+func levelFromClassical(classical string) int {
+	switch classical {
+	case "P256", "X25519":
+		return 1
+	case "P384":
+		return 3
+	case "P521":
+		return 5
+	}
+	return 0
+}
+
+// Parses the classes of the post-quantum algorithms of the KEMTLS and PQTLS handshakes: hybrid, pure or both
+func ParseClasses(name string) ([]Class, error) {
+	switch strings.ToLower(name) {
+	case "hybrid":
+		return []Class{Hybrid}, nil
+	case "pure":
+		return []Class{Pure}, nil
+	case "both":
+		return []Class{Hybrid, Pure}, nil
+	}
+	return nil, fmt.Errorf("unknown algorithm class %q (hybrid, pure or both)", name)
+}
+
+func (k Kind) String() string {
+	if k == Signature {
+		return "Signature"
+	}
+	return "KEM"
+}
+
+func (c Class) String() string {
+	switch c {
+	case Pure:
+		return "Pure PQ"
+	case Classic:
+		return "Classic"
+	}
+	return "Hybrid"
+}
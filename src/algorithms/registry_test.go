@@ -0,0 +1,107 @@
+package algorithms
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/kem"
+	"crypto/liboqs_sig"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+)
+
+func TestKEMNames(t *testing.T) {
+	for _, a := range Default.Algorithms {
+		if a.Kind != KEM {
+			continue
+		}
+
+		if name, err := CurveIDToName(a.CurveID); err != nil || name != a.Name {
+			t.Errorf("CurveIDToName(%#x) = %s, %v, want %s", uint16(a.CurveID), name, err, a.Name)
+		}
+		if curveID, err := NameToCurveID(a.Name); err != nil || curveID != a.CurveID {
+			t.Errorf("NameToCurveID(%s) = %#x, %v, want %#x", a.Name, uint16(curveID), err, uint16(a.CurveID))
+		}
+
+		// The classic key exchanges are not liboqs KEMs
+		if a.Class == Classic {
+			continue
+		}
+		pub, _, err := kem.GenerateKey(rand.Reader, kem.ID(a.CurveID))
+		if err != nil {
+			t.Errorf("%s: generating a KEM key: %v", a.Name, err)
+			continue
+		}
+		if pub.KEMId != kem.ID(a.CurveID) {
+			t.Errorf("%s: KEM key of ID %#x, want %#x", a.Name, uint16(pub.KEMId), uint16(a.CurveID))
+		}
+	}
+}
+
+func TestSignatureNames(t *testing.T) {
+	for _, a := range Default.Algorithms {
+		if a.Kind != Signature || a.Class == Classic {
+			continue
+		}
+
+		if sigID, err := liboqs_sig.NameToSigID(a.Name); err != nil || sigID != a.SigID {
+			t.Errorf("liboqs_sig.NameToSigID(%s) = %#x, %v, want %#x", a.Name, uint16(sigID), err, uint16(a.SigID))
+		}
+		if sigID, err := NameToSigID(a.Name); err != nil || sigID != a.SigID {
+			t.Errorf("NameToSigID(%s) = %#x, %v, want %#x", a.Name, uint16(sigID), err, uint16(a.SigID))
+		}
+		if name, err := SigIDToName(a.SigID); err != nil || name != a.Name {
+			t.Errorf("SigIDToName(%#x) = %s, %v, want %s", uint16(a.SigID), name, err, a.Name)
+		}
+
+		pub, _, err := liboqs_sig.GenerateKey(a.SigID)
+		if err != nil {
+			t.Errorf("%s: generating a signature key: %v", a.Name, err)
+			continue
+		}
+		if pub.SigId != a.SigID {
+			t.Errorf("%s: signature key of ID %#x, want %#x", a.Name, uint16(pub.SigId), uint16(a.SigID))
+		}
+	}
+}
+
+func TestClassicSignatureNames(t *testing.T) {
+	for _, a := range Default.Algorithms {
+		if a.Kind != Signature || a.Class != Classic {
+			continue
+		}
+
+		algo, err := NameToClassicSigAlgo(a.Name)
+		if err != nil {
+			t.Errorf("NameToClassicSigAlgo(%s): %v", a.Name, err)
+			continue
+		}
+
+		var priv interface{}
+		switch algo := algo.(type) {
+		case elliptic.Curve:
+			priv, err = ecdsa.GenerateKey(algo, rand.Reader)
+		case RSAKeySize:
+			priv, err = rsa.GenerateKey(rand.Reader, int(algo))
+		default:
+			t.Errorf("%s: unknown classic signature algorithm %T", a.Name, algo)
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: generating a signature key: %v", a.Name, err)
+			continue
+		}
+
+		if name, err := ClassicSigToName(priv); err != nil || name != a.Name {
+			t.Errorf("ClassicSigToName(%s key) = %s, %v, want %s", a.Name, name, err, a.Name)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	for _, a := range Default.Algorithms {
+		if a.Level != 1 && a.Level != 3 && a.Level != 5 {
+			t.Errorf("%s: NIST level %d, want 1, 3 or 5", a.Name, a.Level)
+		}
+	}
+}
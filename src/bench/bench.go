@@ -1,4 +1,5 @@
-package main
+// Package bench measures the KEM and signature algorithms, outside of the TLS handshakes.
+package bench
 
 import (
 	"bufio"
@@ -8,18 +9,15 @@ import (
 	"crypto/rand"
 	"encoding/csv"
 	"encoding/hex"
-	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
-)
 
-// Flags
-var (
-	reps = flag.Int("reps", 10, "Repetition count for each algorithm benchmark")
+	"tls_tests/src/algorithms"
+	"tls_tests/src/stats"
 )
 
-
 // Types
 type kemBenchmarkResult struct {
 	kemName string
@@ -54,17 +52,40 @@ var classicKEMAlgorithms = []string{
 	"KEM_P256", "KEM_P384", "KEM_P521",
 }
 
-var benchmarkKEMAlgorithms = []string {	 
-	"P521_HQC_256",
-	// "KEM_P256", "P256_Kyber512", "P256_HQC_128", "P256_BIKE_L1", "P256_Classic_McEliece_348864",
-	// "KEM_P384",	"P384_Kyber768", "P384_HQC_192", "P384_BIKE_L3", "P384_Classic_McEliece_460896",
-	// "KEM_P521", "P521_Kyber1024", "P521_HQC_256", "P521_BIKE_L5", "P521_Classic_McEliece_6688128",
+// KEMs to be benchmarked, selected from the default registry. The classic KEMs are also accepted by name.
+// An empty selection is replaced by the default one.
+func KEMAlgorithms(selection string) (names []string, err error) {
+	if selection == "" {
+		selection = algorithms.DefaultBenchmarkKEMSelection
+	}
+
+	var registrySelection []string
+	for _, name := range strings.Split(selection, ",") {
+		if contains(classicKEMAlgorithms, name) {
+			names = append(names, name)
+		} else {
+			registrySelection = append(registrySelection, name)
+		}
+	}
+	if len(registrySelection) == 0 {
+		return names, nil
+	}
+
+	registryNames, err := algorithms.Default.Select(strings.Join(registrySelection, ","), algorithms.KEM, algorithms.Hybrid, algorithms.Pure)
+	if err != nil {
+		return nil, err
+	}
+	return append(names, registryNames...), nil
 }
 
-var benchmarkSignatureAlgorithms = []string {	 
-	"P256_Dilithium2", "P256_Falcon512",
-	"P384_Dilithium3",
-	"P521_Dilithium5", "P521_Falcon1024",
+// Signature algorithms to be benchmarked, selected from the default registry. An empty selection is replaced
+// by the default one.
+func SignatureAlgorithms(selection string) ([]string, error) {
+	if selection == "" {
+		selection = algorithms.DefaultBenchmarkSigsSelection
+	}
+
+	return algorithms.Default.Select(selection, algorithms.Signature, algorithms.Hybrid, algorithms.Pure)
 }
 
 func contains(s []string, e string) bool {
@@ -76,7 +97,9 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-func benchmarkSignatures() {
+// Measures the signing and verification of each signature algorithm in reps repetitions, saving the results
+// in csv/signature_benchmark.csv
+func BenchmarkSignatures(names []string, reps int) {
 
 	var measurements []float64
 	var benchmarkResults []signatureBenchmarkResult
@@ -96,7 +119,7 @@ func benchmarkSignatures() {
 		panic(err)
 	}
 
-	for _, sigName := range benchmarkSignatureAlgorithms {
+	for _, sigName := range names {
 
 		sigId, err := liboqs_sig.NameToSigID(sigName)
 		if err != nil {
@@ -121,7 +144,7 @@ func benchmarkSignatures() {
 
 		// Signing benchmark
 		measurements = nil
-		for i := 0; i < *reps; i++ {
+		for i := 0; i < reps; i++ {
 			start = time.Now()
 			_, err := priv.Sign(rand.Reader, handshakeToBeSignedData, signOpts)
 			if err != nil {
@@ -133,11 +156,11 @@ func benchmarkSignatures() {
 			measurements = append(measurements, elapsedMs)
 		}
 
-		sigAvg, sigStdev := computeStats(measurements)
+		sigAvg, sigStdev := stats.ComputeStats(measurements)
 
 		// Verify benchmark
 		measurements = nil
-		for i := 0; i < *reps; i++ {
+		for i := 0; i < reps; i++ {
 			start = time.Now()
 			ok, err := pub.Verify(handshakeToBeSignedData, signature)
 			if err != nil {
@@ -152,10 +175,10 @@ func benchmarkSignatures() {
 			measurements = append(measurements, elapsedMs)
 		}
 
-		verAvg, verStdev := computeStats(measurements)
+		verAvg, verStdev := stats.ComputeStats(measurements)
 
 		
-		secLevel := getSecurityLevel(sigName)		
+		secLevel := algorithms.SecurityLevel(sigName)		
 		pubBytes := pub.MarshalBinary();
 
 		result := signatureBenchmarkResult{
@@ -174,14 +197,16 @@ func benchmarkSignatures() {
 	saveBenchmarkSignaturesCsv(benchmarkResults)	
 }
 
-func benchmarkKEMs() {
+// Measures the key generation, encapsulation and decapsulation of each KEM in reps repetitions, saving the
+// results in csv/kem_benchmark.csv
+func BenchmarkKEMs(names []string, reps int) {
 	var measurements []float64
 	var benchmarkResults []kemBenchmarkResult
 	var elapsedMs float64
 	var start, finish time.Time
 	var elapsed time.Duration
 
-	for _, kemName := range benchmarkKEMAlgorithms {
+	for _, kemName := range names {
 
 		isClassicKEM := contains(classicKEMAlgorithms, kemName)
 
@@ -189,7 +214,7 @@ func benchmarkKEMs() {
 		if isClassicKEM {
 			kemId = classicKEMIdMap[kemName]
 		} else {
-			kemCurveId, err := nameToCurveID(kemName)
+			kemCurveId, err := algorithms.NameToCurveID(kemName)
 			if err != nil {
 				panic(err)
 			}
@@ -207,7 +232,7 @@ func benchmarkKEMs() {
 
 		// Keygen benchmark
 		measurements = nil
-		for i := 0; i < *reps; i++ {
+		for i := 0; i < reps; i++ {
 			start = time.Now()
 			_, _, err = kem.GenerateKey(rand.Reader, kemId)
 			finish = time.Now()
@@ -218,12 +243,12 @@ func benchmarkKEMs() {
 			elapsedMs = float64(elapsed) / float64(time.Millisecond)
 			measurements = append(measurements, elapsedMs)
 		}
-		kgAvg, kgStdev := computeStats(measurements)
+		kgAvg, kgStdev := stats.ComputeStats(measurements)
 
 		
 		// Encapsulation benchmark
 		measurements = nil
-		for i := 0; i < *reps; i++ {
+		for i := 0; i < reps; i++ {
 			start = time.Now()
 			_, _, err = kem.Encapsulate(rand.Reader, publicKey)
 			if err != nil {
@@ -234,11 +259,11 @@ func benchmarkKEMs() {
 			elapsedMs = float64(elapsed) / float64(time.Millisecond)
 			measurements = append(measurements, elapsedMs)
 		}
-		encAvg, encStdev := computeStats(measurements)
+		encAvg, encStdev := stats.ComputeStats(measurements)
 		
 		// Decapsulation benchmark
 		measurements = nil
-		for i := 0; i < *reps; i++ {
+		for i := 0; i < reps; i++ {
 			start = time.Now()
 			_, err = kem.Decapsulate(privateKey, ciphertext)
 			if err != nil {
@@ -250,7 +275,7 @@ func benchmarkKEMs() {
 			measurements = append(measurements, elapsedMs)
 		}
 
-		decAvg, decStdev := computeStats(measurements)
+		decAvg, decStdev := stats.ComputeStats(measurements)
 
 
 		kemDetails, err := kem.GetKemDetails(kemId)
@@ -278,13 +303,6 @@ func benchmarkKEMs() {
 
 }
 
-func main() {
-
-	flag.Parse()	
-	// benchmarkSignatures()
-	benchmarkKEMs()
-}
-
 func saveBenchmarkKEMsCsv(benchmarkResults []kemBenchmarkResult) {
 	csvFile, err := os.Create("csv/kem_benchmark.csv")
 	if err != nil {
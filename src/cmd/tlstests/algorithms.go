@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"tls_tests/src/algorithms"
+)
+
+// Prints the algorithms of the registry. If -kexlist or -authlist are set, only the selected algorithms are printed.
+func runAlgorithms(args []string) {
+	fs := newFlagSet("algorithms")
+	sel := registerSelectionFlags(fs)
+
+	registry := algorithms.Default
+	selected := registry.Algorithms
+
+	parse(fs, args, func() error {
+		if *sel.kexList == "" && *sel.authList == "" {
+			return nil
+		}
+		selected = nil
+
+		classes := []algorithms.Class{algorithms.Hybrid, algorithms.Pure, algorithms.Classic}
+		selections := []struct {
+			selection string
+			kind      algorithms.Kind
+		}{{*sel.kexList, algorithms.KEM}, {*sel.authList, algorithms.Signature}}
+
+		for _, s := range selections {
+			if s.selection == "" {
+				continue
+			}
+			names, err := registry.Select(s.selection, s.kind, classes...)
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				algo, _ := registry.Get(name)
+				selected = append(selected, algo)
+			}
+		}
+		return nil
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tType\tClass\tFamily\tClassical\tNIST Level\tID\tPublic key size\tCiphertext size")
+
+	for _, a := range selected {
+		var id string
+		switch {
+		case a.Kind == algorithms.KEM:
+			id = fmt.Sprintf("0x%04x", uint16(a.CurveID))
+		case a.Class != algorithms.Classic:
+			id = fmt.Sprintf("%d", a.SigID)
+		default:
+			id = "-"
+		}
+
+		publicKeySize, ciphertextSize := "-", "-"
+		if a.PublicKeySize > 0 {
+			publicKeySize = fmt.Sprintf("%d", a.PublicKeySize)
+			ciphertextSize = fmt.Sprintf("%d", a.CiphertextSize)
+		}
+
+		classical := a.Classical
+		if classical == "" {
+			classical = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", a.Name, a.Kind, a.Class, a.Family, classical, a.Level, id, publicKeySize, ciphertextSize)
+	}
+	w.Flush()
+}
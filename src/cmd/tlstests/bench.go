@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+
+	"tls_tests/src/bench"
+)
+
+// Benchmarks the KEMs selected by -kexlist and, with -signatures, the signature algorithms selected by -authlist
+func runBench(args []string) {
+	fs := newFlagSet("bench")
+	sel := registerSelectionFlags(fs)
+	reps := fs.Int("reps", 10, "Repetition count for each algorithm benchmark")
+	signatures := fs.Bool("signatures", false, "Also benchmark the signature algorithms")
+
+	var kems, sigs []string
+
+	parse(fs, args, func() error {
+		if *reps <= 0 {
+			return errors.New("-reps must be positive")
+		}
+		if *sel.authList != "" && !*signatures {
+			return errors.New("-authlist requires -signatures")
+		}
+
+		var err error
+		if kems, err = bench.KEMAlgorithms(*sel.kexList); err != nil {
+			return err
+		}
+		if *signatures {
+			sigs, err = bench.SignatureAlgorithms(*sel.authList)
+		}
+		return err
+	})
+
+	bench.BenchmarkKEMs(kems, *reps)
+	if *signatures {
+		bench.BenchmarkSignatures(sigs, *reps)
+	}
+}
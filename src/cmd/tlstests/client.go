@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"tls_tests/src/experiment"
+)
+
+// Performs the handshakes with the servers negotiated with the control server or, with -sync=false, with the
+// servers launched by the server command with the same flags. In both cases, the manifest of the servers is
+// verified before any handshake.
+func runClient(args []string) {
+	fs := newFlagSet("client")
+	tf := registerTLSFlags(fs)
+	sel := registerSelectionFlags(fs)
+	sel.registerMatrix(fs)
+	handshakes := fs.Int("handshakes", 1, "Number of Handshakes desired")
+	initCwnd := registerInitCwndFlag(fs)
+	resumption := registerResumptionFlag(fs)
+	keyShare := registerKeyShareFlag(fs)
+	sni := registerSNIFlag(fs)
+	concurrent := registerConcurrentFlag(fs)
+	workers := registerWorkersFlag(fs)
+	outliers := registerOutliersFlag(fs)
+	warmup := registerWarmupFlag(fs)
+	order := registerOrderFlags(fs)
+	precision := registerPrecisionFlags(fs)
+	synchronize := registerSyncFlag(fs)
+
+	var keysKEX, keysAuth []string
+
+	parse(fs, args, func() error {
+		if err := tf.validate(); err != nil {
+			return err
+		}
+		if *tf.ipServer == "" {
+			return errors.New("-ipserver is required")
+		}
+		if err := validateHandshakes(*handshakes); err != nil {
+			return err
+		}
+		if err := validateInitCwnd(*initCwnd); err != nil {
+			return err
+		}
+		if err := validateKeyShare(*keyShare); err != nil {
+			return err
+		}
+		if err := validateWorkers(*workers); err != nil {
+			return err
+		}
+		if err := validateWarmup(*warmup); err != nil {
+			return err
+		}
+		if err := order.validate(*workers); err != nil {
+			return err
+		}
+		if err := precision.validate(*handshakes); err != nil {
+			return err
+		}
+
+		var err error
+		keysKEX, keysAuth, err = sel.tests(tf.mode())
+		return err
+	})
+
+	fmt.Println("Starting clients...")
+	fmt.Printf("Process PID is %d\n", os.Getpid())
+
+	cfg := experimentConfig(tf, *handshakes, false, "")
+	sel.configure(cfg)
+	cfg.InitCwnd = *initCwnd
+	cfg.Resumption = *resumption
+	cfg.KeyShare = *keyShare
+	cfg.SNI = *sni
+	cfg.Concurrent = *concurrent
+	cfg.Workers = *workers
+	cfg.RejectOutliers = *outliers
+	cfg.Warmup = *warmup
+	cfg.Interleave = *order.interleave
+	cfg.Seed = *order.seed
+	cfg.Precision = *precision.precision / 100
+	cfg.MaxHandshakes = *precision.maxHandshakes
+
+	ctl := experiment.DialControl(*tf.ipServer)
+
+	if !*synchronize {
+		servers, err := ctl.Manifest(cfg, keysKEX, keysAuth)
+		ctl.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := experiment.RunClientHandshakes(cfg, servers, nil); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	servers, err := ctl.Negotiate(cfg, keysKEX, keysAuth, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := experiment.RunClientHandshakes(cfg, servers, ctl); err != nil {
+		if _, ok := err.(*experiment.PeerError); !ok {
+			ctl.ReportError(err)
+		}
+		log.Fatal(err)
+	}
+
+	if err := ctl.Shutdown(); err != nil {
+		log.Fatal(err)
+	}
+}
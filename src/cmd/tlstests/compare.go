@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"tls_tests/src/stats"
+)
+
+// Exit status of compare when a regression exceeds the threshold, distinct from the usage (2) and the failures (1)
+const regressionExitStatus = 3
+
+// Compares the timings of each pair of algorithms between two sets of client results files, exiting with
+// regressionExitStatus if some timing got slower than the threshold
+func runCompare(args []string) {
+	fs := newFlagSet("compare")
+	base := fs.String("base", "", "Comma separated client results files of the base run (e.g. old/kemtls-client.csv,old/pqtls-client.csv)")
+	current := fs.String("new", "", "Comma separated client results files of the new run, compared with the base file in the same position")
+	threshold := fs.Float64("threshold", 5, "Regression threshold: slowdown of the mean timing, in percent of the base mean")
+	alpha := fs.Float64("alpha", 0.05, "Significance level of the Mann-Whitney U test of the regressions")
+
+	var baseFiles, currentFiles []string
+
+	parse(fs, args, func() error {
+		if strings.TrimSpace(*base) == "" || strings.TrimSpace(*current) == "" {
+			return errors.New("-base and -new are required")
+		}
+		baseFiles, currentFiles = splitList(*base), splitList(*current)
+		if len(baseFiles) != len(currentFiles) {
+			return fmt.Errorf("-base has %d files and -new has %d", len(baseFiles), len(currentFiles))
+		}
+		if *threshold < 0 {
+			return errors.New("-threshold must not be negative")
+		}
+		if *alpha <= 0 || *alpha >= 1 {
+			return errors.New("-alpha must be between 0 and 1")
+		}
+		return nil
+	})
+
+	regressions := 0
+	for i := range baseFiles {
+		baseHeader, baseMeasurements, err := stats.ReadClientResults(baseFiles[i])
+		if err != nil {
+			log.Fatal(err)
+		}
+		currentHeader, currentMeasurements, err := stats.ReadClientResults(currentFiles[i])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		comparisons, err := stats.Compare(baseHeader, baseMeasurements, currentHeader, currentMeasurements)
+		if err != nil {
+			log.Fatalf("%s and %s: %v", baseFiles[i], currentFiles[i], err)
+		}
+
+		fmt.Printf("%s -> %s\n\n", baseFiles[i], currentFiles[i])
+		stats.PrintComparisons(comparisons, *threshold/100, *alpha)
+		fmt.Printf("\n\n")
+
+		for _, c := range comparisons {
+			if c.Regression(*threshold/100, *alpha) {
+				regressions++
+			}
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Printf("Regressed timings, slower by more than %.2f%% (p < %g): %d\n", *threshold, *alpha, regressions)
+		os.Exit(regressionExitStatus)
+	}
+	fmt.Printf("No timing regressed by more than %.2f%% (p < %g)\n", *threshold, *alpha)
+}
+
+// Splits a comma separated list of files
+func splitList(list string) []string {
+	var files []string
+	for _, file := range strings.Split(list, ",") {
+		files = append(files, strings.TrimSpace(file))
+	}
+	return files
+}
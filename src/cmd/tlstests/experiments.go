@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"tls_tests/src/experiment"
+)
+
+// Runs the experiments of a JSON spec file as the client host, negotiating each of them with the server host
+func runExperiments(args []string) {
+	fs := newFlagSet("experiments")
+	specFile := fs.String("spec", "", "Path to the JSON experiment spec file")
+	role := fs.String("role", "", "Role of this host in the experiments (server or client)")
+	dryRun := fs.Bool("dryrun", false, "Only print the runs expanded from the spec file")
+
+	parse(fs, args, func() error {
+		if *role == "server" && !*dryRun {
+			if *specFile != "" {
+				return errors.New("-spec only applies to the client, which negotiates each experiment with the server")
+			}
+			return nil
+		}
+		if *specFile == "" {
+			return errors.New("-spec is required")
+		}
+		if *dryRun {
+			return nil
+		}
+		if *role != "client" {
+			return fmt.Errorf("-role must be server or client, got %q", *role)
+		}
+		return nil
+	})
+
+	if *role == "server" && !*dryRun {
+		fmt.Printf("Process PID is %d\n", os.Getpid())
+		fmt.Println("Waiting for the experiments of the client...")
+
+		if err := experiment.ServeControl(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	spec, err := experiment.ReadSpec(*specFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	runs, err := spec.Expand()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		for _, run := range runs {
+			fmt.Println(run.String())
+		}
+		return
+	}
+
+	fmt.Printf("Process PID is %d\n", os.Getpid())
+	fmt.Printf("Running %d experiments from %s as %s\n", len(runs), *specFile, *role)
+
+	if err := experiment.RunClient(spec, runs); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/experiment"
+	"tls_tests/src/handshake"
+	"tls_tests/src/netem"
+	"tls_tests/src/pki"
+)
+
+// Flags of the TLS clients and servers, shared by the server, client and load commands
+type tlsFlags struct {
+	hybridRoot      *string
+	rootCert        *string
+	rootKey         *string
+	ipServer        *string
+	ipClient        *string
+	clientAuth      *bool
+	pqtls           *bool
+	classic         *bool
+	cachedCert      *bool
+	classicMcEliece *bool
+	link            *string
+	rootLevel       *int
+	chainDepth      *int
+	intermediates   *string
+}
+
+func registerTLSFlags(fs *flag.FlagSet) *tlsFlags {
+	return &tlsFlags{
+		hybridRoot:      fs.String("hybridroot", "", "Hybrid Root CA Algorithm family name"),
+		rootCert:        fs.String("rootcert", "", "Path to the classic Root CA certificate PEM file"),
+		rootKey:         fs.String("rootkey", "", "Path to the classic Root CA private key PEM file"),
+		ipServer:        fs.String("ipserver", "", "IP of the KEMTLS/TLS Server"),
+		ipClient:        fs.String("ipclient", "", "IP of the KEMTLS/TLS Client Auth Certificate"),
+		clientAuth:      fs.Bool("clientauth", false, "Client authentication"),
+		pqtls:           fs.Bool("pqtls", false, "PQTLS"),
+		classic:         fs.Bool("classic", false, "Classic TLS"),
+		cachedCert:      fs.Bool("cachedcert", false, "KEMTLS PDK or TLS(cached) server cert."),
+		classicMcEliece: fs.Bool("classicmceliece", false, "Classic McEliece tests"),
+		rootLevel:       fs.Int("rootlevel", pki.DefaultRootLevel, "NIST level of the Hybrid Root CA of -hybridroot: 1, 3 or 5"),
+		chainDepth:      fs.Int("chaindepth", pki.DefaultChainDepth, "Number of Intermediate CAs of the certificate chain"),
+		intermediates: fs.String("intermediates", "", "Comma separated signature algorithms of the Intermediate CAs, from the one "+
+			"issued by the Root CA down, or a single one for all of them (defaults to ECDSA or Dilithium in the level of the chain)"),
+		link: fs.String("link", "none", "Emulated link: none, a preset ("+strings.Join(netem.PresetNames(), ", ")+
+			"), or delay, jitter, bandwidth, loss and rto settings (e.g. delay=20ms,bandwidth=10mbit,loss=0.01)"),
+	}
+}
+
+func (f *tlsFlags) validate() error {
+	if *f.pqtls && *f.classic {
+		return errors.New("-pqtls and -classic are mutually exclusive")
+	}
+
+	if *f.rootCert == "" && *f.hybridRoot == "" {
+		return errors.New("one of -hybridroot or -rootcert is required")
+	}
+	if *f.rootCert != "" && *f.hybridRoot != "" {
+		return errors.New("-hybridroot and -rootcert are mutually exclusive")
+	}
+	if (*f.rootCert == "") != (*f.rootKey == "") {
+		return errors.New("-rootcert and -rootkey must be set together")
+	}
+	if *f.hybridRoot != "" {
+		if _, err := algorithms.Default.Find(algorithms.Signature, algorithms.Hybrid, *f.hybridRoot, 5); err != nil {
+			return fmt.Errorf("unknown -hybridroot family %q", *f.hybridRoot)
+		}
+	}
+
+	if *f.rootLevel != 1 && *f.rootLevel != 3 && *f.rootLevel != 5 {
+		return errors.New("-rootlevel must be 1, 3 or 5")
+	}
+	if *f.rootCert != "" && *f.rootLevel != pki.DefaultRootLevel {
+		return errors.New("-rootlevel only applies to -hybridroot")
+	}
+	if err := f.chainShape().Check(f.mode() == handshake.Classic); err != nil {
+		return fmt.Errorf("invalid -chaindepth or -intermediates: %v", err)
+	}
+
+	if *f.classicMcEliece && f.mode() != handshake.KEMTLS {
+		return errors.New("-classicmceliece only applies to KEMTLS")
+	}
+	if *f.clientAuth && *f.ipClient == "" {
+		return errors.New("-clientauth requires -ipclient")
+	}
+	if _, err := netem.Parse(*f.link); err != nil {
+		return fmt.Errorf("invalid -link: %v", err)
+	}
+
+	return nil
+}
+
+// TLS mode selected by -pqtls and -classic
+func (f *tlsFlags) mode() handshake.Mode {
+	if *f.classic {
+		return handshake.Classic
+	} else if *f.pqtls {
+		return handshake.PQTLS
+	}
+	return handshake.KEMTLS
+}
+
+// Options of the clients and servers set by the flags
+func (f *tlsFlags) options(handshakes int) handshake.Options {
+	return handshake.Options{
+		Mode:            f.mode(),
+		CachedCert:      *f.cachedCert,
+		ClassicMcEliece: *f.classicMcEliece,
+		ClientAuth:      *f.clientAuth,
+		ServerIP:        *f.ipServer,
+		ClientIP:        *f.ipClient,
+		Root:            pki.RootCA{HybridFamily: *f.hybridRoot, CertFile: *f.rootCert, KeyFile: *f.rootKey, Level: *f.rootLevel},
+		Chain:           f.chainShape(),
+		Handshakes:      handshakes,
+		Link:            f.linkProfile(),
+	}
+}
+
+// Intermediate CAs of the certificate chain selected by -chaindepth and -intermediates
+func (f *tlsFlags) chainShape() pki.ChainShape {
+	shape := pki.ChainShape{Depth: *f.chainDepth}
+	if *f.intermediates != "" {
+		for _, name := range strings.Split(*f.intermediates, ",") {
+			shape.Algorithms = append(shape.Algorithms, strings.TrimSpace(name))
+		}
+	}
+	return shape
+}
+
+// Emulated link selected by -link, once validated
+func (f *tlsFlags) linkProfile() netem.Profile {
+	link, err := netem.Parse(*f.link)
+	if err != nil {
+		panic(err)
+	}
+	return link
+}
+
+// Flags selecting the algorithms to be tested
+type selectionFlags struct {
+	kexList    *string
+	authList   *string
+	class      *string
+	crossLevel *bool
+	pairList   *string
+
+	// Pairs parsed from -pairs by tests
+	pairs []experiment.AlgorithmPair
+}
+
+func registerSelectionFlags(fs *flag.FlagSet) *selectionFlags {
+	return &selectionFlags{
+		kexList:  fs.String("kexlist", "", "Comma separated key exchange algorithms to be tested, by name, family or level (e.g. HQC,L3)"),
+		authList: fs.String("authlist", "", "Comma separated signature algorithms to be tested, by name, family or level (e.g. Dilithium,L5)"),
+	}
+}
+
+// Registers the flags of the algorithm matrix of the handshake commands: -class, which selects the hybrid or
+// pure post-quantum algorithms, and -crosslevel and -pairs, which test combinations in different security levels
+func (f *selectionFlags) registerMatrix(fs *flag.FlagSet) {
+	f.class = fs.String("class", "hybrid", "Class of the post-quantum algorithms to be tested: hybrid, pure or both (not with -classic)")
+	f.crossLevel = fs.Bool("crosslevel", false, "Also test the combinations of algorithms in different security levels")
+	f.pairList = fs.String("pairs", "", "Comma separated KEX:Auth pairs to be tested instead of the -kexlist and -authlist "+
+		"combinations, in any security level (e.g. X25519:ECDSA_P521)")
+}
+
+// Returns the key exchange and the signature algorithms selected by -kexlist, -authlist and -class, or the
+// default ones of the classic TLS mode or of the post-quantum modes. With -pairs, the algorithms of the pairs
+// are returned instead.
+func (f *selectionFlags) tests(mode handshake.Mode) (keysKEX, keysAuth []string, err error) {
+	if f.pairList != nil && *f.pairList != "" {
+		if *f.kexList != "" || *f.authList != "" || *f.class != "hybrid" || *f.crossLevel {
+			return nil, nil, errors.New("-pairs is exclusive with -kexlist, -authlist, -class and -crosslevel")
+		}
+		f.pairs, err = experiment.ParsePairs(*f.pairList, mode)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid -pairs: %v", err)
+		}
+		keysKEX, keysAuth = experiment.PairAlgorithms(f.pairs)
+		return keysKEX, keysAuth, nil
+	}
+
+	if mode == handshake.Classic {
+		if f.class != nil && *f.class != "hybrid" {
+			return nil, nil, errors.New("-class does not apply to -classic")
+		}
+		return algorithms.SelectTests(*f.kexList, *f.authList, algorithms.Classic)
+	}
+
+	classes := []algorithms.Class{algorithms.Hybrid}
+	if f.class != nil {
+		classes, err = algorithms.ParseClasses(*f.class)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid -class: %v", err)
+		}
+	}
+	return algorithms.SelectTests(*f.kexList, *f.authList, classes...)
+}
+
+// Sets the cross level combinations and the pairs selected by the flags in the experiment configuration
+func (f *selectionFlags) configure(cfg *experiment.Config) {
+	if f.crossLevel == nil {
+		return
+	}
+	cfg.CrossLevel = *f.crossLevel || len(f.pairs) > 0
+	cfg.Pairs = f.pairs
+}
+
+// Checks that handshakes is positive
+func validateHandshakes(handshakes int) error {
+	if handshakes <= 0 {
+		return errors.New("-handshakes must be positive")
+	}
+	return nil
+}
+
+// Registers -sync, which negotiates the experiment with the server through the control protocol
+func registerSyncFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("sync", true, "Negotiate the experiment with the server through the control protocol, at port "+
+		experiment.ControlPort+". When the client finishes, the server ends its execution.")
+}
+
+// Registers -initcwnd, the TCP initial congestion window that the first server flight of the client handshakes
+// is checked against
+func registerInitCwndFlag(fs *flag.FlagSet) *int {
+	return fs.Int("initcwnd", netem.DefaultInitCwnd, fmt.Sprintf("TCP initial congestion window, in segments of %d bytes, "+
+		"that the first server flight is checked against", netem.MSS))
+}
+
+// Registers -resumption, which follows each full client handshake by a handshake resuming its session
+func registerResumptionFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("resumption", false, "Follow each full handshake by a handshake resuming its session with a PSK, "+
+		"measuring both")
+}
+
+// Registers -sni, which serves all the combinations of algorithms at a single port, selected by server name
+func registerSNIFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("sni", false, "Serve all the combinations at a single port, selecting each one by the server name (SNI) "+
+		"of the client, e.g. p256-hqc-128.kemtls.test")
+}
+
+// Registers -concurrent, which handles the connections of the handshake servers concurrently
+func registerConcurrentFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("concurrent", false, "Handle the connections of the servers concurrently, each one in its own goroutine, "+
+		"to measure their handshake throughput")
+}
+
+// Registers -keyshare, the key exchange of the initial key share of the client handshakes
+func registerKeyShareFlag(fs *flag.FlagSet) *string {
+	return fs.String("keyshare", "", "Key exchange of the initial key share of the client (e.g. X25519). The servers of "+
+		"other key exchanges answer with a HelloRetryRequest. Defaults to the key exchange of each server")
+}
+
+// Checks that keyShare is empty or a known key exchange
+func validateKeyShare(keyShare string) error {
+	if keyShare == "" {
+		return nil
+	}
+	if _, err := algorithms.NameToCurveID(keyShare); err != nil {
+		return fmt.Errorf("unknown -keyshare %q", keyShare)
+	}
+	return nil
+}
+
+// Registers -workers, the goroutines performing the client handshakes of each server concurrently
+func registerWorkersFlag(fs *flag.FlagSet) *int {
+	return fs.Int("workers", 1, "Goroutines performing the handshakes of each server concurrently (see -concurrent)")
+}
+
+// Registers -outliers, which rejects the outliers of the timings before summarizing their distributions
+func registerOutliersFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("outliers", false, "Reject the timings outside of the Tukey fences (1.5 interquartile ranges beyond the "+
+		"quartiles) before summarizing their distributions. The results files keep every timing")
+}
+
+// Registers -warmup, the discarded handshakes performed with each server before the measured ones
+func registerWarmupFlag(fs *flag.FlagSet) *int {
+	return fs.Int("warmup", 0, "Successful handshakes of each kind performed with each server before the measured ones, "+
+		"which are not saved")
+}
+
+// Checks that warmup is not negative
+func validateWarmup(warmup int) error {
+	if warmup < 0 {
+		return errors.New("-warmup must not be negative")
+	}
+	return nil
+}
+
+// Flags of the order of the client handshakes
+type orderFlags struct {
+	interleave *bool
+	seed       *int64
+}
+
+// Registers -interleave and -seed, which perform the handshakes in rounds over all the servers in a random order
+func registerOrderFlags(fs *flag.FlagSet) *orderFlags {
+	return &orderFlags{
+		interleave: fs.Bool("interleave", false, "Perform the handshakes in rounds of one handshake with each server, in "+
+			"a random order, instead of measuring the servers one after the other"),
+		seed: fs.Int64("seed", 0, "Seed of the random order of -interleave, recorded in the flights file (0 for a "+
+			"random seed)"),
+	}
+}
+
+// Checks that the order flags apply, with the workers of the handshakes
+func (f *orderFlags) validate(workers int) error {
+	if *f.seed != 0 && !*f.interleave {
+		return errors.New("-seed only applies to -interleave")
+	}
+	if *f.interleave && workers > 1 {
+		return errors.New("-interleave performs the handshakes one at a time, it does not apply to -workers")
+	}
+	return nil
+}
+
+// Flags of the adaptive number of handshakes
+type precisionFlags struct {
+	precision     *float64
+	maxHandshakes *int
+}
+
+// Registers -precision and -maxhandshakes, which measure each server until its timings are precise enough
+func registerPrecisionFlags(fs *flag.FlagSet) *precisionFlags {
+	return &precisionFlags{
+		precision: fs.Float64("precision", 0, "Measure each server until the half-width of the 95% confidence interval "+
+			"of the mean full protocol time falls below this percent of the mean, in at least -handshakes and at most "+
+			"-maxhandshakes handshakes (0 to always perform -handshakes)"),
+		maxHandshakes: fs.Int("maxhandshakes", 0, "Maximum number of handshakes of each server with -precision"),
+	}
+}
+
+// Checks that the precision flags are consistent with the minimum number of handshakes
+func (f *precisionFlags) validate(handshakes int) error {
+	if *f.precision < 0 {
+		return errors.New("-precision must not be negative")
+	}
+	if *f.precision == 0 {
+		if *f.maxHandshakes != 0 {
+			return errors.New("-maxhandshakes only applies to -precision")
+		}
+		return nil
+	}
+	if handshakes < 2 {
+		return errors.New("-precision requires at least 2 -handshakes, for a confidence interval")
+	}
+	if *f.maxHandshakes < handshakes {
+		return fmt.Errorf("-precision requires -maxhandshakes of at least -handshakes (%d)", handshakes)
+	}
+	return nil
+}
+
+// Checks that workers is positive
+func validateWorkers(workers int) error {
+	if workers <= 0 {
+		return errors.New("-workers must be positive")
+	}
+	return nil
+}
+
+// Checks that initCwnd is positive
+func validateInitCwnd(initCwnd int) error {
+	if initCwnd <= 0 {
+		return errors.New("-initcwnd must be positive")
+	}
+	return nil
+}
+
+// Experiment configuration of the server and client commands
+func experimentConfig(f *tlsFlags, handshakes int, isHTTP bool, auth string) *experiment.Config {
+	return &experiment.Config{Options: f.options(handshakes), HTTP: isHTTP, Auth: auth}
+}
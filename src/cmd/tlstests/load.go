@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	netURL "net/url"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/experiment"
+	"tls_tests/src/handshake"
+	"tls_tests/src/loadtest"
+)
+
+// Flags of the load command, based on the gobench tool
+type loadFlags struct {
+	requests         *int64
+	period           *int64
+	clients          *int
+	url              *string
+	urlsFilePath     *string
+	keepAlive        *bool
+	postDataFilePath *string
+	writeTimeout     *int
+	readTimeout      *int
+	authHeader       *string
+	kexAlgo          *string
+	authAlgo         *string
+}
+
+func getHostFromURL(url string) string {
+	u, err := netURL.Parse(url)
+	if err != nil {
+		panic(err)
+	}
+
+	host, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		panic(err)
+	}
+	return host
+}
+
+// Replaces the port of url with the one of the server negotiated with the control server
+func setURLPort(url string, port int) string {
+	u, err := netURL.Parse(url)
+	if err != nil {
+		panic(err)
+	}
+
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	return u.String()
+}
+
+func readLines(path string) (lines []string, err error) {
+
+	var file *os.File
+	var part []byte
+	var prefix bool
+
+	if file, err = os.Open(path); err != nil {
+		return
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	buffer := bytes.NewBuffer(make([]byte, 0))
+	for {
+		if part, prefix, err = reader.ReadLine(); err != nil {
+			break
+		}
+		buffer.Write(part)
+		if !prefix {
+			lines = append(lines, buffer.String())
+			buffer.Reset()
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return
+}
+
+func newLoadConfiguration(lf *loadFlags, opts *handshake.Options) *loadtest.Configuration {
+	configuration := &loadtest.Configuration{
+		URLs:       make([]string, 0),
+		Method:     "GET",
+		PostData:   nil,
+		KeepAlive:  *lf.keepAlive,
+		Requests:   int64((1 << 63) - 1),
+		AuthHeader: *lf.authHeader}
+
+	if *lf.period != -1 {
+		configuration.Period = *lf.period
+
+		timeout := make(chan bool, 1)
+		go func() {
+			<-time.After(time.Duration(*lf.period) * time.Second)
+			timeout <- true
+		}()
+
+		go func() {
+			<-timeout
+			pid := os.Getpid()
+			proc, _ := os.FindProcess(pid)
+			err := proc.Signal(os.Interrupt)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+		}()
+	}
+
+	if *lf.requests != -1 {
+		configuration.Requests = *lf.requests
+	}
+
+	if *lf.urlsFilePath != "" {
+		fileLines, err := readLines(*lf.urlsFilePath)
+
+		if err != nil {
+			log.Fatalf("Error in ioutil.ReadFile for file: %s Error: %v", *lf.urlsFilePath, err)
+		}
+
+		configuration.URLs = fileLines
+	}
+
+	if *lf.url != "" {
+		configuration.URLs = append(configuration.URLs, *lf.url)
+	}
+
+	if *lf.postDataFilePath != "" {
+		configuration.Method = "POST"
+
+		data, err := ioutil.ReadFile(*lf.postDataFilePath)
+
+		if err != nil {
+			log.Fatalf("Error in ioutil.ReadFile for file path: %s Error: %v", *lf.postDataFilePath, err)
+		}
+
+		configuration.PostData = data
+	}
+
+	configuration.MyClient.ReadTimeout = time.Duration(*lf.readTimeout) * time.Millisecond
+	configuration.MyClient.WriteTimeout = time.Duration(*lf.writeTimeout) * time.Millisecond
+	configuration.MyClient.MaxConnsPerHost = *lf.clients
+
+	configuration.MyClient.Dial = loadtest.MyDialer(opts.Link)
+
+	loadtest.InitTLSConfig(configuration, opts, *lf.kexAlgo, *lf.authAlgo, *lf.url)
+
+	return configuration
+}
+
+// Performs an HTTP load test against the HTTPS server launched by the server command with -http
+func runLoad(args []string) {
+	fs := newFlagSet("load")
+	tf := registerTLSFlags(fs)
+	synchronize := registerSyncFlag(fs)
+
+	lf := &loadFlags{
+		requests:         fs.Int64("r", -1, "Number of requests per client"),
+		clients:          fs.Int("c", 100, "Number of concurrent clients"),
+		url:              fs.String("u", "", "URL"),
+		urlsFilePath:     fs.String("f", "", "URL's file path (line seperated)"),
+		keepAlive:        fs.Bool("k", true, "Do HTTP keep-alive"),
+		postDataFilePath: fs.String("d", "", "HTTP POST data file path"),
+		period:           fs.Int64("t", -1, "Period of time (in seconds)"),
+		writeTimeout:     fs.Int("tw", 5000, "Write timeout (in milliseconds)"),
+		readTimeout:      fs.Int("tr", 5000, "Read timeout (in milliseconds)"),
+		authHeader:       fs.String("auth", "", "Authorization header"),
+		kexAlgo:          fs.String("benchkex", "P256_Kyber512", "Kex algorithm"),
+		authAlgo:         fs.String("benchauth", "P256_Dilithium2", "Authentication algorithm"),
+	}
+
+	parse(fs, args, func() error {
+		if err := tf.validate(); err != nil {
+			return err
+		}
+		if *tf.ipServer != "" {
+			return errors.New("-ipserver does not apply to load, the server is given by -u")
+		}
+
+		if *lf.urlsFilePath == "" && *lf.url == "" {
+			return errors.New("-u or -f is required")
+		}
+		if *lf.url == "" && (*tf.cachedCert || *synchronize) {
+			return errors.New("-cachedcert and -sync require the server -u")
+		}
+		if *lf.url != "" {
+			if _, err := netURL.Parse(*lf.url); err != nil {
+				return fmt.Errorf("invalid -u: %v", err)
+			}
+		}
+		if *lf.requests == -1 && *lf.period == -1 {
+			return errors.New("Requests or period must be provided")
+		}
+		if *lf.requests != -1 && *lf.period != -1 {
+			return errors.New("Only one should be provided: [requests|period]")
+		}
+		if *lf.clients <= 0 {
+			return errors.New("-c must be positive")
+		}
+
+		if _, err := algorithms.NameToCurveID(*lf.kexAlgo); err != nil {
+			return fmt.Errorf("unknown -benchkex %q", *lf.kexAlgo)
+		}
+		if _, err := algorithms.Default.Get(*lf.authAlgo); err != nil {
+			return fmt.Errorf("unknown -benchauth %q", *lf.authAlgo)
+		}
+		return nil
+	})
+
+	opts := tf.options(0)
+	clients := *lf.clients
+
+	// The HTTPS server is launched by the control server, which chooses its port
+	var ctl *experiment.ControlClient
+	var server experiment.Server
+
+	if *synchronize {
+		host := getHostFromURL(*lf.url)
+		opts.ServerIP = host
+
+		cfg := &experiment.Config{Options: opts, HTTP: true, Auth: *lf.authAlgo}
+
+		ctl = experiment.DialControl(host)
+
+		servers, err := ctl.Negotiate(cfg, []string{*lf.kexAlgo}, []string{*lf.authAlgo}, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		server = servers[0]
+		*lf.url = setURLPort(*lf.url, server.Port)
+
+		if err := ctl.Start(server); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	startTime := time.Now()
+	var done sync.WaitGroup
+	results := make(map[int]*loadtest.Result)
+
+	saveResultsAndNotifyServer := func() {
+		elapsed := int64(time.Since(startTime).Seconds())
+
+		if elapsed == 0 {
+			elapsed = 1
+		}
+
+		readThroughput, writeThroughput := loadtest.Throughput()
+		loadtest.SaveCSV(&opts, *lf.kexAlgo, *lf.authAlgo, clients, results, elapsed, readThroughput, writeThroughput)
+
+		if ctl != nil {
+			if err := ctl.Stop(server); err != nil {
+				log.Println(err)
+			}
+			if err := ctl.Shutdown(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	signalChannel := make(chan os.Signal, 2)
+	signal.Notify(signalChannel, os.Interrupt)
+	go func() {
+		_ = <-signalChannel
+		saveResultsAndNotifyServer()
+		os.Exit(0)
+	}()
+
+	configuration := newLoadConfiguration(lf, &opts)
+
+	goMaxProcs := os.Getenv("GOMAXPROCS")
+
+	if goMaxProcs == "" {
+		runtime.GOMAXPROCS(runtime.NumCPU())
+	}
+
+	fmt.Printf("Dispatching %d clients\n", clients)
+
+	done.Add(clients)
+	for i := 0; i < clients; i++ {
+		result := &loadtest.Result{}
+		results[i] = result
+		go loadtest.Client(configuration, result, &done)
+
+	}
+	fmt.Println("Waiting for results...")
+	done.Wait()
+	saveResultsAndNotifyServer()
+}
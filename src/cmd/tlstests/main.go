@@ -0,0 +1,91 @@
+// Command tlstests runs the Hybrid KEMTLS, Hybrid PQTLS and classic TLS experiments. Each subcommand has its
+// own flags, which are validated before any measurement starts.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
+
+var commands = []command{
+	{"server", "Launch the TLS or HTTPS servers of the experiment", runServer},
+	{"client", "Perform the handshakes with the servers launched by server", runClient},
+	{"load", "Perform an HTTP load test against an HTTPS server", runLoad},
+	{"selftest", "Run the servers and the client handshakes in this process, on loopback", runSelfTest},
+	{"root", "Generate a Root CA in root_ca/", runRoot},
+	{"bench", "Benchmark the KEM and signature algorithms outside of TLS", runBench},
+	{"plot", "Draw the graphs of KEMTLS client results in graphs/", runPlot},
+	{"report", "Print the statistics of client results files", runReport},
+	{"compare", "Compare two sets of client results files, failing on regressions", runCompare},
+	{"experiments", "Run the experiments of a JSON spec file", runExperiments},
+	{"algorithms", "List the registered algorithms", runAlgorithms},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", os.Args[0])
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "-h" || name == "-help" || name == "help" {
+		usage()
+		return
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			c.run(os.Args[2:])
+			return
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+// Creates the flag set of a command
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags]\n\n", os.Args[0], name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// Parses the command arguments, exiting with the usage if they are not valid
+func parse(fs *flag.FlagSet, args []string, validate func() error) {
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected arguments: %v\n\n", fs.Args())
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if validate == nil {
+		return
+	}
+	if err := validate(); err != nil {
+		fmt.Fprintf(fs.Output(), "%s: %v\n\n", fs.Name(), err)
+		fs.Usage()
+		os.Exit(2)
+	}
+}
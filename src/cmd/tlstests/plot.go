@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"gonum.org/v1/plot/plotter"
+
+	"tls_tests/src/plot"
+	"tls_tests/src/stats"
+)
+
+// Draws the graphs of a KEMTLS client results file
+func runPlot(args []string) {
+	fs := newFlagSet("plot")
+	results := fs.String("results", "csv/kemtls-client.csv", "KEMTLS client results file")
+	graphs := fs.String("graphs", "all", "Graphs to be drawn: bar, boxplot, html or all")
+	link := fs.String("link", "", "Link profile of the measurements to be drawn, if the results file has several")
+	chain := fs.String("chain", "", "Certificate chain of the measurements to be drawn, if the results file has several")
+	kind := fs.String("handshake", "", "Kind of the handshakes to be drawn, full or resumed, if the results file has both")
+
+	var header []string
+	var measurements []stats.Measurements
+
+	parse(fs, args, func() error {
+		switch *graphs {
+		case "bar", "boxplot", "html", "all":
+		default:
+			return fmt.Errorf("unknown -graphs %q", *graphs)
+		}
+
+		var err error
+		header, measurements, err = stats.ReadClientResults(*results)
+		if err != nil {
+			return err
+		}
+		if !stats.IsKEMTLSHeader(header) {
+			return errors.New("-results is not a KEMTLS client results file")
+		}
+
+		links := stats.Links(measurements)
+		if *link == "" && len(links) > 1 {
+			return fmt.Errorf("-results has several link profiles (%s), select one with -link", strings.Join(links, ", "))
+		}
+		chains := stats.Chains(measurements)
+		if *chain == "" && len(chains) > 1 {
+			return fmt.Errorf("-results has several certificate chains (%s), select one with -chain", strings.Join(chains, ", "))
+		}
+
+		kinds := stats.Handshakes(measurements)
+		if *kind == "" && len(kinds) > 1 {
+			return fmt.Errorf("-results has several kinds of handshakes (%s), select one with -handshake", strings.Join(kinds, ", "))
+		}
+
+		var selected []stats.Measurements
+		for _, m := range measurements {
+			if (*link == "" || m.Link == *link) && (*chain == "" || m.Chain == *chain) && (*kind == "" || m.Handshake == *kind) {
+				selected = append(selected, m)
+			}
+		}
+		if len(selected) == 0 {
+			return errors.New("no measurements of -link, -chain and -handshake in -results")
+		}
+		measurements = selected
+		return nil
+	})
+
+	var list []stats.KEMTLSClientResultsInfo
+	for _, m := range measurements {
+		r, err := m.KEMTLSStats()
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = append(list, r)
+	}
+
+	if *graphs == "bar" || *graphs == "all" {
+		for _, metric := range plot.Metrics {
+			plot.Genbar(list, metric)
+		}
+	}
+
+	if *graphs == "boxplot" || *graphs == "all" {
+		var names []string
+		var vals []plotter.Values
+		for _, m := range measurements {
+			names = append(names, m.KEXName)
+			vals = append(vals, plotter.Values(m.Timings[0]))
+		}
+		plot.Boxplot(names, vals, len(measurements))
+	}
+
+	if *graphs == "html" || *graphs == "all" {
+		plot.BarMarkLines(list, "All")
+	}
+}
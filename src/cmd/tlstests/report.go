@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"tls_tests/src/stats"
+)
+
+// Prints the statistics of each pair of algorithms of the client results files
+func runReport(args []string) {
+	fs := newFlagSet("report")
+	results := fs.String("results", "csv/kemtls-client.csv", "Comma separated client results files (e.g. csv/kemtls-client.csv,csv/pqtls-client.csv)")
+	outliers := registerOutliersFlag(fs)
+
+	parse(fs, args, func() error {
+		if strings.TrimSpace(*results) == "" {
+			return errors.New("-results is required")
+		}
+		return nil
+	})
+
+	for _, fileName := range strings.Split(*results, ",") {
+		fileName = strings.TrimSpace(fileName)
+
+		header, measurements, err := stats.ReadClientResults(fileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("%s\n\n", fileName)
+
+		// The statistics of each setup are printed separately
+		chains := stats.Chains(measurements)
+		for _, setup := range stats.Setups(measurements) {
+			var selected []stats.Measurements
+			for _, m := range measurements {
+				if m.Setup == setup {
+					selected = append(selected, m)
+				}
+			}
+
+			described := false
+			if setup.Handshake != stats.FullHandshake {
+				fmt.Printf("Handshake: %s\n", setup.Handshake)
+				described = true
+			}
+			if setup.KeyShare != "" {
+				fmt.Printf("Key share: %s\n", setup.KeyShare)
+				described = true
+			}
+			if setup.Link != "none" {
+				fmt.Printf("Link: %s\n", setup.Link)
+				described = true
+			}
+			if len(chains) > 1 {
+				fmt.Printf("Chain: %s\n", setup.Chain)
+				described = true
+			}
+			if described {
+				fmt.Println()
+			}
+
+			printStatistics(fileName, header, selected)
+			fmt.Println()
+			printSummaries(header, selected, *outliers)
+			fmt.Printf("\n\n")
+		}
+	}
+}
+
+// Prints the statistics of the measurements of a client results file
+func printStatistics(fileName string, header []string, measurements []stats.Measurements) {
+	if stats.IsKEMTLSHeader(header) {
+		var list []stats.KEMTLSClientResultsInfo
+		for _, m := range measurements {
+			r, err := m.KEMTLSStats()
+			if err != nil {
+				log.Fatalf("%s: %v", fileName, err)
+			}
+			list = append(list, r)
+		}
+		stats.KEMTLSPrintStatistics(list)
+		return
+	}
+
+	var list []stats.TLSClientResultsInfo
+	for _, m := range measurements {
+		r, err := m.TLSStats()
+		if err != nil {
+			log.Fatalf("%s: %v", fileName, err)
+		}
+		list = append(list, r)
+	}
+	stats.TLSPrintStatistics(list)
+}
+
+// Prints the distribution of the first timing of the measurements, the full protocol time
+func printSummaries(header []string, measurements []stats.Measurements, rejectOutliers bool) {
+	var summaries []stats.CombinationSummary
+	for _, m := range measurements {
+		summaries = append(summaries, stats.CombinationSummary{
+			KEXName:  m.KEXName,
+			AuthName: m.AuthName,
+			Summary:  stats.Summarize(m.Timings[0], rejectOutliers),
+		})
+	}
+	stats.PrintSummaries(stats.MetricName(header[2]), summaries)
+}
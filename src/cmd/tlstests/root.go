@@ -0,0 +1,40 @@
+package main
+
+import (
+	"crypto/liboqs_sig"
+	"fmt"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/pki"
+)
+
+// Generates a Root CA to be used in the tests
+func runRoot(args []string) {
+	fs := newFlagSet("root")
+	rootAlgo := fs.String("algo", "P256_Dilithium2", "Root CA Algorithm")
+	classic := fs.Bool("classic", false, "Generate a Root CA with classic algorithms")
+
+	var rootClassicAlgo interface{}
+	var rootLiboqsID liboqs_sig.ID
+
+	parse(fs, args, func() error {
+		var err error
+		if *classic {
+			rootClassicAlgo, err = algorithms.NameToClassicSigAlgo(*rootAlgo)
+		} else {
+			rootLiboqsID, err = algorithms.NameToSigID(*rootAlgo)
+		}
+		if err != nil {
+			return fmt.Errorf("unknown -algo %q", *rootAlgo)
+		}
+		return nil
+	})
+
+	if *classic {
+		pki.GenerateClassicRoot(*rootAlgo, rootClassicAlgo)
+		return
+	}
+
+	curve, _ := liboqs_sig.ClassicFromSig(rootLiboqsID)
+	pki.GenerateHybridRoot(*rootAlgo, rootLiboqsID, curve)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"tls_tests/src/experiment"
+)
+
+// Runs the servers and the client handshakes in this process, on loopback
+func runSelfTest(args []string) {
+	fs := newFlagSet("selftest")
+	tf := registerTLSFlags(fs)
+	sel := registerSelectionFlags(fs)
+	sel.registerMatrix(fs)
+	handshakes := fs.Int("handshakes", 1, "Number of Handshakes desired")
+	initCwnd := registerInitCwndFlag(fs)
+	resumption := registerResumptionFlag(fs)
+	keyShare := registerKeyShareFlag(fs)
+	sni := registerSNIFlag(fs)
+	concurrent := registerConcurrentFlag(fs)
+	workers := registerWorkersFlag(fs)
+	outliers := registerOutliersFlag(fs)
+	warmup := registerWarmupFlag(fs)
+	order := registerOrderFlags(fs)
+	precision := registerPrecisionFlags(fs)
+	maxFailures := fs.Int("maxfailures", 10, "Consecutive failed handshakes after which a combination fails")
+
+	var keysKEX, keysAuth []string
+
+	parse(fs, args, func() error {
+		if *tf.ipServer != "" {
+			return errors.New("-ipserver does not apply to selftest, the servers listen on loopback")
+		}
+		if *tf.ipClient == "" {
+			*tf.ipClient = "127.0.0.1"
+		}
+		if err := tf.validate(); err != nil {
+			return err
+		}
+		if err := validateHandshakes(*handshakes); err != nil {
+			return err
+		}
+		if err := validateInitCwnd(*initCwnd); err != nil {
+			return err
+		}
+		if err := validateKeyShare(*keyShare); err != nil {
+			return err
+		}
+		if err := validateWorkers(*workers); err != nil {
+			return err
+		}
+		if err := validateWarmup(*warmup); err != nil {
+			return err
+		}
+		if err := order.validate(*workers); err != nil {
+			return err
+		}
+		if err := precision.validate(*handshakes); err != nil {
+			return err
+		}
+		if *maxFailures <= 0 {
+			return errors.New("-maxfailures must be positive")
+		}
+
+		var err error
+		keysKEX, keysAuth, err = sel.tests(tf.mode())
+		return err
+	})
+
+	fmt.Println("Starting self test...")
+	fmt.Printf("Process PID is %d\n\n", os.Getpid())
+
+	cfg := experimentConfig(tf, *handshakes, false, "")
+	sel.configure(cfg)
+	cfg.InitCwnd = *initCwnd
+	cfg.Resumption = *resumption
+	cfg.KeyShare = *keyShare
+	cfg.SNI = *sni
+	cfg.Concurrent = *concurrent
+	cfg.Workers = *workers
+	cfg.RejectOutliers = *outliers
+	cfg.Warmup = *warmup
+	cfg.Interleave = *order.interleave
+	cfg.Seed = *order.seed
+	cfg.Precision = *precision.precision / 100
+	cfg.MaxHandshakes = *precision.maxHandshakes
+	cfg.MaxFailures = *maxFailures
+
+	if err := experiment.SelfTest(cfg, keysKEX, keysAuth); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/experiment"
+)
+
+// Launches the servers of the experiments negotiated by the clients through the control protocol or, with
+// -sync=false, the servers of each combination of the selected algorithms or a single HTTPS server
+func runServer(args []string) {
+	fs := newFlagSet("server")
+	tf := registerTLSFlags(fs)
+	sel := registerSelectionFlags(fs)
+	sel.registerMatrix(fs)
+	handshakes := fs.Int("handshakes", 1, "Number of Handshakes desired")
+	isHTTP := fs.Bool("http", false, "Launch an HTTPS server for the load tests")
+	kex := fs.String("kex", "", "Key Exchange algorithm of the HTTPS server")
+	auth := fs.String("authserver", "", "Authentication algorithm of the HTTPS server")
+	sni := registerSNIFlag(fs)
+	concurrent := registerConcurrentFlag(fs)
+	warmup := registerWarmupFlag(fs)
+	target := fs.Int("target", 0, "Successful handshakes after which each server stops (0 for no limit)")
+	timeout := fs.Duration("timeout", 0, "Time after which the servers stop (0 for no limit)")
+	synchronize := fs.Bool("sync", true, "Launch the servers negotiated by the clients through the control protocol, "+
+		"at port "+experiment.ControlPort+". The experiment flags only apply to -sync=false, where the port only "+
+		"publishes the manifest of the servers.")
+
+	var keysKEX, keysAuth []string
+
+	parse(fs, args, func() error {
+		if *synchronize {
+			var err error
+			fs.Visit(func(f *flag.Flag) {
+				if f.Name != "sync" && err == nil {
+					err = fmt.Errorf("-%s is negotiated by the client, it only applies to -sync=false", f.Name)
+				}
+			})
+			return err
+		}
+
+		if err := tf.validate(); err != nil {
+			return err
+		}
+		if *tf.ipServer == "" {
+			return errors.New("-ipserver is required")
+		}
+		if err := validateHandshakes(*handshakes); err != nil {
+			return err
+		}
+		if *target < 0 || *timeout < 0 {
+			return errors.New("-target and -timeout must not be negative")
+		}
+		if err := validateWarmup(*warmup); err != nil {
+			return err
+		}
+
+		if !*isHTTP {
+			if *kex != "" || *auth != "" {
+				return errors.New("-kex and -authserver only apply to -http servers, use -kexlist and -authlist")
+			}
+
+			var err error
+			keysKEX, keysAuth, err = sel.tests(tf.mode())
+			return err
+		}
+
+		if *sel.kexList != "" || *sel.authList != "" || *sel.class != "hybrid" || *sel.crossLevel || *sel.pairList != "" {
+			return errors.New("-kexlist, -authlist, -class, -crosslevel and -pairs do not apply to -http servers, use -kex and -authserver")
+		}
+		if *sni || *concurrent || *warmup != 0 {
+			return errors.New("-sni, -concurrent and -warmup do not apply to -http servers")
+		}
+		if *target != 0 || *timeout != 0 {
+			return errors.New("-target and -timeout do not apply to -http servers")
+		}
+		if *kex == "" || *auth == "" {
+			return errors.New("-http requires -kex and -authserver")
+		}
+		if _, err := algorithms.NameToCurveID(*kex); err != nil {
+			return fmt.Errorf("unknown -kex %q", *kex)
+		}
+		if _, err := algorithms.Default.Get(*auth); err != nil {
+			return fmt.Errorf("unknown -authserver %q", *auth)
+		}
+
+		keysKEX, keysAuth = []string{*kex}, []string{*auth}
+		return nil
+	})
+
+	fmt.Println("Starting servers...")
+	fmt.Printf("Process PID is %d\n\n", os.Getpid())
+
+	if *synchronize {
+		if err := experiment.ServeControl(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// The servers stop on an interrupt, saving their partial results
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if *timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	cfg := experimentConfig(tf, *handshakes, *isHTTP, *auth)
+	sel.configure(cfg)
+	cfg.SNI = *sni
+	cfg.Concurrent = *concurrent
+	cfg.Warmup = *warmup
+	experiment.ReportSkipped(cfg, keysKEX, keysAuth)
+	servers := experiment.Plan(cfg, keysKEX, keysAuth, experiment.DefaultFirstPort)
+	launched, err := experiment.LaunchServers(ctx, cfg, servers, *target)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The manifest is published to the clients while the servers run
+	go func() {
+		if err := experiment.ServeManifest(servers); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	if *isHTTP {
+		<-ctx.Done()
+		return
+	}
+
+	summaries := launched.Wait()
+
+	fmt.Println()
+	for _, s := range servers {
+		fmt.Printf("Stopped port %d  |  KEX: %s  Auth: %s  |  %s\n", s.Port, s.KEX, s.Auth, summaries[s.Endpoint()])
+	}
+}
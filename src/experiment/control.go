@@ -0,0 +1,240 @@
+package experiment
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"tls_tests/src/handshake"
+)
+
+// Port of the control protocol, where the server waits for the clients to negotiate the experiments
+const ControlPort = "9000"
+
+// Port of the first server launched by the control server, unless the first client requests another one
+const DefaultFirstPort = 4433
+
+// Largest control message accepted
+const maxMessageSize = 1 << 20
+
+// Types of the control messages. The client negotiates an experiment with hello, and the server answers ready
+// with the manifest of its launched servers. The manifest of the current experiment, or of a standalone server,
+// is also answered to manifest. The client signals the measurements of each server with start and stop,
+// acknowledged with started and stopped, and ends the session with shutdown, acknowledged with bye. Any
+// request may be answered with error instead.
+const (
+	MessageHello    = "hello"
+	MessageReady    = "ready"
+	MessageManifest = "manifest"
+	MessageStart    = "start"
+	MessageStarted  = "started"
+	MessageStop     = "stop"
+	MessageStopped  = "stopped"
+	MessageDiscard  = "discard"
+	MessageShutdown = "shutdown"
+	MessageBye      = "bye"
+	MessageError    = "error"
+)
+
+// Message of the control protocol
+type Message struct {
+	Type string `json:"type"`
+
+	// hello: the experiment and its algorithm matrix, and the port of its first server, if it is the first
+	// experiment of the server
+	Config    *Config  `json:"config,omitempty"`
+	KEX       []string `json:"kex,omitempty"`
+	Auth      []string `json:"auth,omitempty"`
+	FirstPort int      `json:"firstPort,omitempty"`
+
+	// ready, manifest: the manifest of the launched servers. start, stop, discard: the measured server
+	Servers []Server `json:"servers,omitempty"`
+
+	// stopped: the summary of the measuring loop of the handshake server
+	Summary *handshake.ServeSummary `json:"summary,omitempty"`
+
+	// error
+	Error string `json:"error,omitempty"`
+}
+
+// Error reported by the peer in an error message
+type PeerError struct {
+	Message string
+}
+
+func (e *PeerError) Error() string {
+	return "peer error: " + e.Message
+}
+
+// Connection of the control protocol. Each message is framed by its length, as a 4 byte big endian integer,
+// followed by its JSON encoding.
+type ControlConn struct {
+	conn net.Conn
+}
+
+func NewControlConn(conn net.Conn) *ControlConn {
+	return &ControlConn{conn: conn}
+}
+
+func (c *ControlConn) Send(msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+
+	_, err = c.conn.Write(frame)
+	return err
+}
+
+func (c *ControlConn) Receive() (*Message, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("control message of %d bytes is too large", size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, payload); err != nil {
+		return nil, err
+	}
+
+	msg := new(Message)
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("invalid control message: %v", err)
+	}
+	return msg, nil
+}
+
+// Receives a message of the expected type. An error message is returned as a *PeerError.
+func (c *ControlConn) Expect(msgType string) (*Message, error) {
+	msg, err := c.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type == MessageError {
+		return nil, &PeerError{Message: msg.Error}
+	}
+	if msg.Type != msgType {
+		return nil, fmt.Errorf("expected %s control message, received %s", msgType, msg.Type)
+	}
+	return msg, nil
+}
+
+// Sends an error message with err
+func (c *ControlConn) SendError(err error) error {
+	return c.Send(&Message{Type: MessageError, Error: err.Error()})
+}
+
+func (c *ControlConn) Close() error {
+	return c.conn.Close()
+}
+
+// Client side of the control protocol
+type ControlClient struct {
+	conn *ControlConn
+}
+
+// Connects to the control server at ip, retrying until it is listening
+func DialControl(ip string) *ControlClient {
+	fmt.Println("Connecting to the control server...")
+
+	for {
+		conn, err := net.Dial("tcp", net.JoinHostPort(ip, ControlPort))
+		if err == nil {
+			return &ControlClient{conn: NewControlConn(conn)}
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// Sends a request, returning the answer of the expected type
+func (c *ControlClient) request(msg *Message, answer string) (*Message, error) {
+	if err := c.conn.Send(msg); err != nil {
+		return nil, err
+	}
+	return c.conn.Expect(answer)
+}
+
+// Negotiates an experiment, returning the verified manifest of the servers launched by the server for it.
+// firstPort is only used by the server if it has not launched servers yet, and may be 0 to use DefaultFirstPort.
+func (c *ControlClient) Negotiate(cfg *Config, keysKEX, keysAuth []string, firstPort int) ([]Server, error) {
+	answer, err := c.request(&Message{Type: MessageHello, Config: cfg, KEX: keysKEX, Auth: keysAuth, FirstPort: firstPort}, MessageReady)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyManifest(cfg, answer.Servers, keysKEX, keysAuth); err != nil {
+		return nil, err
+	}
+	return answer.Servers, nil
+}
+
+// Fetches the manifest of the servers already launched by the server, such as a standalone one, verifying it
+// against the servers expected by cfg, keysKEX and keysAuth
+func (c *ControlClient) Manifest(cfg *Config, keysKEX, keysAuth []string) ([]Server, error) {
+	answer, err := c.request(&Message{Type: MessageManifest}, MessageManifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyManifest(cfg, answer.Servers, keysKEX, keysAuth); err != nil {
+		return nil, err
+	}
+	return answer.Servers, nil
+}
+
+func (c *ControlClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *ControlClient) Start(s Server) error {
+	_, err := c.request(&Message{Type: MessageStart, Servers: []Server{s}}, MessageStarted)
+	return err
+}
+
+// Signals the end of the measurements of s, printing the summary of the server
+func (c *ControlClient) Stop(s Server) error {
+	answer, err := c.request(&Message{Type: MessageStop, Servers: []Server{s}}, MessageStopped)
+	if err != nil {
+		return err
+	}
+	if answer.Summary != nil {
+		fmt.Printf("Server summary: %s\n", answer.Summary)
+	}
+	return nil
+}
+
+// Signals the end of the measurements of s, given up by the client, whose results are discarded by the server
+func (c *ControlClient) Discard(s Server) error {
+	answer, err := c.request(&Message{Type: MessageDiscard, Servers: []Server{s}}, MessageStopped)
+	if err != nil {
+		return err
+	}
+	if answer.Summary != nil {
+		fmt.Printf("Server summary: %s\n", answer.Summary)
+	}
+	return nil
+}
+
+// Reports err to the server, which aborts the current experiment
+func (c *ControlClient) ReportError(err error) {
+	c.conn.SendError(err)
+}
+
+// Ends the session, after which the control server exits
+func (c *ControlClient) Shutdown() error {
+	defer c.conn.Close()
+
+	_, err := c.request(&Message{Type: MessageShutdown}, MessageBye)
+	return err
+}
@@ -0,0 +1,221 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+)
+
+// Server side of the control protocol
+type controlServer struct {
+	// Port of the next server to be launched, or 0 before the first experiment
+	nextPort int
+
+	// Servers of the current experiment, and their measuring loops
+	servers  []Server
+	launched *Launched
+
+	// Set for a standalone server, which only publishes the manifest of the servers launched from its flags
+	standalone bool
+}
+
+// Launches the servers of the experiments negotiated by the clients at ControlPort, one client at a time,
+// until a client shuts the control server down
+func ServeControl() error {
+	return (&controlServer{}).serve()
+}
+
+// Publishes the manifest of the servers of a standalone server at ControlPort, until the process is killed
+func ServeManifest(servers []Server) error {
+	return (&controlServer{servers: servers, standalone: true}).serve()
+}
+
+func (cs *controlServer) serve() error {
+	ln, err := net.Listen("tcp", net.JoinHostPort("0.0.0.0", ControlPort))
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	fmt.Printf("Waiting for clients at the control port %s...\n", ControlPort)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+
+		shutdown, err := cs.serveSession(NewControlConn(conn))
+		conn.Close()
+
+		if err != nil {
+			log.Printf("Control session with %s ended: %v\n", conn.RemoteAddr(), err)
+			cs.finish()
+		}
+		if shutdown {
+			return nil
+		}
+	}
+}
+
+// Serves the requests of a client until it shuts the server down or the connection is closed
+func (cs *controlServer) serveSession(conn *ControlConn) (shutdown bool, err error) {
+	for {
+		msg, err := conn.Receive()
+		if err == io.EOF {
+			// The clients of a standalone server close the connection once they have the manifest
+			if cs.standalone {
+				return false, nil
+			}
+			return false, errors.New("connection closed by the client")
+		}
+		if err != nil {
+			return false, err
+		}
+
+		var answer *Message
+
+		switch {
+		case cs.standalone && msg.Type != MessageManifest:
+			err = fmt.Errorf("standalone server launched with -sync=false, it only answers %s control messages", MessageManifest)
+		case msg.Type == MessageManifest:
+			answer, err = cs.manifest()
+		case msg.Type == MessageHello:
+			answer, err = cs.launch(msg)
+		case msg.Type == MessageStart:
+			answer, err = cs.measure(msg, "Measuring", MessageStarted)
+		case msg.Type == MessageStop:
+			answer, err = cs.measure(msg, "Measured", MessageStopped)
+		case msg.Type == MessageDiscard:
+			answer, err = cs.measure(msg, "Discarded", MessageStopped)
+		case msg.Type == MessageShutdown:
+			cs.finish()
+			return true, conn.Send(&Message{Type: MessageBye})
+		case msg.Type == MessageError:
+			// The client aborted the experiment
+			log.Printf("Client error: %s\n", msg.Error)
+			cs.finish()
+			continue
+		default:
+			err = fmt.Errorf("unexpected %s control message", msg.Type)
+		}
+
+		if err != nil {
+			log.Println(err)
+			if err := conn.SendError(err); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err := conn.Send(answer); err != nil {
+			return false, err
+		}
+	}
+}
+
+// Stops the servers of the current experiment that are still measuring, saving their partial results
+func (cs *controlServer) finish() {
+	if cs.standalone || cs.launched == nil {
+		return
+	}
+
+	summaries := cs.launched.StopAll()
+	for _, s := range cs.servers {
+		if summary, ok := summaries[s.Endpoint()]; ok {
+			fmt.Printf("Stopped port %d  |  KEX: %s  Auth: %s  |  %s\n", s.Port, s.KEX, s.Auth, summary)
+		}
+	}
+
+	cs.servers = nil
+	cs.launched = nil
+}
+
+// Launches the servers of the experiment negotiated by a hello message
+func (cs *controlServer) launch(msg *Message) (*Message, error) {
+	cfg := msg.Config
+	if cfg == nil {
+		return nil, errors.New("hello without the experiment configuration")
+	}
+	if !cfg.HTTP && cfg.Handshakes <= 0 {
+		return nil, errors.New("the number of handshakes must be positive")
+	}
+	if err := checkAlgorithms(msg.KEX, msg.Auth, cfg.Mode); err != nil {
+		return nil, err
+	}
+	if err := checkPairs(cfg.Pairs, cfg.Mode); err != nil {
+		return nil, err
+	}
+
+	cs.finish()
+
+	if cs.nextPort == 0 {
+		cs.nextPort = DefaultFirstPort
+		if msg.FirstPort > 0 {
+			cs.nextPort = msg.FirstPort
+		}
+	}
+
+	fmt.Printf("\nExperiment %s: KEX: %v  Auth: %v  Handshakes: %d\n\n", cfg.Mode, msg.KEX, msg.Auth, cfg.Handshakes)
+
+	ReportSkipped(cfg, msg.KEX, msg.Auth)
+	servers := Plan(cfg, msg.KEX, msg.Auth, cs.nextPort)
+	if len(servers) == 0 {
+		return nil, ErrNoPairs
+	}
+
+	launched, err := LaunchServers(context.Background(), cfg, servers, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	cs.servers = servers
+	cs.launched = launched
+	cs.nextPort = NextPort(cfg, servers, cs.nextPort)
+
+	return &Message{Type: MessageReady, Servers: servers}, nil
+}
+
+// Answers the manifest of the current experiment
+func (cs *controlServer) manifest() (*Message, error) {
+	if len(cs.servers) == 0 {
+		return nil, errors.New("no experiment launched")
+	}
+	return &Message{Type: MessageManifest, Servers: cs.servers}, nil
+}
+
+// Acknowledges the start or the stop of the measurements of a server of the current experiment. Once
+// stopped, a handshake server saves its partial results, or discards them if the client gave it up, and its
+// summary is answered to the client.
+func (cs *controlServer) measure(msg *Message, action, answer string) (*Message, error) {
+	if len(msg.Servers) != 1 {
+		return nil, fmt.Errorf("%s message must refer to one server", msg.Type)
+	}
+	s := msg.Servers[0]
+
+	for _, launched := range cs.servers {
+		if launched != s {
+			continue
+		}
+
+		reply := &Message{Type: answer, Servers: msg.Servers}
+
+		stop := cs.launched.Stop
+		if msg.Type == MessageDiscard {
+			stop = cs.launched.Discard
+		}
+		if msg.Type == MessageStop || msg.Type == MessageDiscard {
+			if summary, ok := stop(s); ok {
+				fmt.Printf("%s port %d  |  KEX: %s  Auth: %s  |  %s\n", action, s.Port, s.KEX, s.Auth, summary)
+				reply.Summary = &summary
+				return reply, nil
+			}
+		}
+
+		fmt.Printf("%s port %d  |  KEX: %s  Auth: %s\n", action, s.Port, s.KEX, s.Auth)
+		return reply, nil
+	}
+	return nil, fmt.Errorf("no server at port %d for KEX %s and Auth %s in the current experiment", s.Port, s.KEX, s.Auth)
+}
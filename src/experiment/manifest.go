@@ -0,0 +1,90 @@
+package experiment
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"tls_tests/src/handshake"
+)
+
+// Error of a server manifest that does not match the servers expected by the client
+type ManifestError struct {
+	Server Server
+	Reason string
+}
+
+func (e *ManifestError) Error() string {
+	// Servers missing from the manifest have no port
+	if e.Server.Port == 0 {
+		return fmt.Sprintf("manifest mismatch for %s server (KEX: %s  Auth: %s): %s",
+			e.Server.Mode, e.Server.KEX, e.Server.Auth, e.Reason)
+	}
+	return fmt.Sprintf("manifest mismatch for %s server at port %d (KEX: %s  Auth: %s): %s",
+		e.Server.Mode, e.Server.Port, e.Server.KEX, e.Server.Auth, e.Reason)
+}
+
+// Checks that the manifest published by the server holds exactly the servers planned by the client for
+// keysKEX and keysAuth, each one with its own endpoint and certificate fingerprint. The ports of the manifest
+// are the ones to be used by the client. In SNI mode, the servers share a port, and each one has the server name
+// expected by the client.
+func VerifyManifest(cfg *Config, manifest []Server, keysKEX, keysAuth []string) error {
+	if len(manifest) == 0 {
+		return errors.New("empty server manifest")
+	}
+
+	planned := Plan(cfg, keysKEX, keysAuth, 0)
+
+	// Server names of the planned combinations
+	expected := make(map[[2]string]string)
+	for _, s := range planned {
+		expected[[2]string{s.KEX, s.Auth}] = s.ServerName
+	}
+
+	endpoints := make(map[string]bool)
+	published := make(map[[2]string]bool)
+
+	for _, s := range manifest {
+		pair := [2]string{s.KEX, s.Auth}
+		serverName, ok := expected[pair]
+
+		switch {
+		case s.Mode != cfg.Mode:
+			return &ManifestError{s, fmt.Sprintf("the client expects %s", cfg.Mode)}
+		case !ok:
+			return &ManifestError{s, "not a combination planned by the client"}
+		case s.ServerName != serverName:
+			return &ManifestError{s, fmt.Sprintf("server name %q, the client expects %q", s.ServerName, serverName)}
+		case published[pair]:
+			return &ManifestError{s, "combination published twice"}
+		case endpoints[s.Endpoint()]:
+			return &ManifestError{s, "port published twice"}
+		case s.Fingerprint == "":
+			return &ManifestError{s, "missing certificate fingerprint"}
+		}
+
+		endpoints[s.Endpoint()] = true
+		published[pair] = true
+	}
+
+	for _, s := range planned {
+		if !published[[2]string{s.KEX, s.Auth}] {
+			s.Port = 0
+			return &ManifestError{s, "missing from the server manifest"}
+		}
+	}
+
+	return nil
+}
+
+// Checks that the certificate presented in a handshake is the one of the manifest
+func (s Server) verifyCertificate(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return &ManifestError{s, "the server did not present its certificate"}
+	}
+
+	if fingerprint := handshake.Fingerprint(state.PeerCertificates[0].Raw); fingerprint != s.Fingerprint {
+		return &ManifestError{s, fmt.Sprintf("certificate fingerprint %s, the manifest publishes %s", fingerprint, s.Fingerprint)}
+	}
+	return nil
+}
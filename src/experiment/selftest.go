@@ -0,0 +1,94 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"tls_tests/src/handshake"
+	"tls_tests/src/pki"
+	"tls_tests/src/stats"
+)
+
+// Progress of the loopback servers, which are stopped once the client finishes their handshakes
+type loopbackServers struct {
+	*Launched
+}
+
+func (l loopbackServers) Start(s Server) error {
+	return nil
+}
+
+func (l loopbackServers) Stop(s Server) error {
+	summary, ok := l.Launched.Stop(s)
+	if !ok {
+		return fmt.Errorf("no server at port %d (KEX: %s  Auth: %s)", s.Port, s.KEX, s.Auth)
+	}
+
+	fmt.Printf("Server summary: %s\n", summary)
+	return nil
+}
+
+func (l loopbackServers) Discard(s Server) error {
+	summary, ok := l.Launched.Discard(s)
+	if !ok {
+		return fmt.Errorf("no server at port %d (KEX: %s  Auth: %s)", s.Port, s.KEX, s.Auth)
+	}
+
+	fmt.Printf("Server summary: %s\n", summary)
+	return nil
+}
+
+// Runs the servers and the client handshakes of an experiment in a single process, on loopback. The servers
+// listen at ephemeral ports, or at a single one in SNI mode, and share the certificate chains of the client. The
+// results are saved in the same files as the ones of separate hosts.
+func SelfTest(cfg *Config, keysKEX, keysAuth []string) error {
+	cfg.ServerIP = "127.0.0.1"
+	cfg.Chains = pki.NewChains(cfg.Root, cfg.Mode == handshake.Classic, cfg.Chain)
+
+	ReportSkipped(cfg, keysKEX, keysAuth)
+	servers := Plan(cfg, keysKEX, keysAuth, 0)
+	if len(servers) == 0 {
+		return ErrNoPairs
+	}
+
+	if cfg.Mode == handshake.KEMTLS {
+		stats.KEMTLSInitCSVServer(cfg.ResultsFiles())
+	} else {
+		stats.TLSInitCSVServer(cfg.ResultsFiles())
+	}
+
+	launched := newLaunched()
+	defer launched.StopAll()
+
+	for i, s := range servers {
+		serverConfig, err := handshake.NewConfig(s.KEX, s.Auth, false, &cfg.Options)
+		if err != nil {
+			return fmt.Errorf("%s server %s/%s: %v", cfg.Mode, s.KEX, s.Auth, err)
+		}
+
+		// In SNI mode, the servers share the listener of the first one
+		var ln net.Listener
+		if i == 0 || !cfg.SNI {
+			ln, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				return err
+			}
+			servers[i].Port = ln.Addr().(*net.TCPAddr).Port
+			ln = cfg.Link.Listener(ln)
+		} else {
+			servers[i].Port = servers[0].Port
+		}
+		servers[i].Fingerprint = handshake.Fingerprint(serverConfig.Certificates[0].Certificate[0])
+
+		address := fmt.Sprintf("127.0.0.1:%d", servers[i].Port)
+		if s.ServerName != "" {
+			address += " (" + s.ServerName + ")"
+		}
+		fmt.Printf("Starting %s server at %s  |  KEX: %s  Auth: %s\n", cfg.Mode, address, s.KEX, s.Auth)
+
+		launched.serve(context.Background(), servers[i], ln, serverConfig, &cfg.Options, 0)
+	}
+
+	return RunClientHandshakes(cfg, servers, loopbackServers{launched})
+}
@@ -0,0 +1,941 @@
+// Package experiment launches the servers and performs the client handshakes of the experiments, either from
+// the command line flags or from a JSON experiment spec file.
+package experiment
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/handshake"
+	"tls_tests/src/loadtest"
+	"tls_tests/src/netem"
+	"tls_tests/src/stats"
+)
+
+// Configuration of the servers and clients of an experiment
+type Config struct {
+	handshake.Options
+
+	// Launch HTTPS servers for the load tests instead of the handshake servers
+	HTTP bool
+
+	// Authentication algorithm of the KEMTLS HTTPS servers. If empty, the key exchange algorithm is used
+	Auth string
+
+	// Consecutive failed handshakes after which the client gives up a server. If 0, the failed handshakes are
+	// retried indefinitely
+	MaxFailures int `json:"-"`
+
+	// Pairs of algorithms to be tested instead of the combinations of the selected ones, in any security level
+	Pairs []AlgorithmPair
+
+	// TCP initial congestion window, in segments, that the first server flight of the client handshakes is
+	// checked against. If 0, netem.DefaultInitCwnd is used
+	InitCwnd int `json:"-"`
+
+	// Goroutines performing the client handshakes of each server concurrently. If 0, the handshakes are
+	// performed one at a time
+	Workers int `json:"-"`
+
+	// Whether the outliers of the client timings are rejected before their distributions are summarized
+	RejectOutliers bool `json:"-"`
+
+	// Perform the client handshakes in rounds of one handshake with each server, in a random order seeded by Seed,
+	// instead of measuring the servers one after the other
+	Interleave bool  `json:"-"`
+	Seed       int64 `json:"-"`
+
+	// If positive, each server is measured until the relative half-width of the 95% confidence interval of the
+	// mean full protocol time falls below Precision, in at least Handshakes and at most MaxHandshakes handshakes
+	Precision     float64 `json:"-"`
+	MaxHandshakes int     `json:"-"`
+}
+
+// Error of the client handshakes when some servers were given up after cfg.MaxFailures failed handshakes
+type FailedServersError struct {
+	Servers []Server
+}
+
+func (e *FailedServersError) Error() string {
+	var failed []string
+	for _, s := range e.Servers {
+		failed = append(failed, fmt.Sprintf("%s/%s (port %d)", s.KEX, s.Auth, s.Port))
+	}
+	return fmt.Sprintf("%d combinations failed: %s", len(e.Servers), strings.Join(failed, ", "))
+}
+
+// Handshake servers measuring in the background until they are stopped
+type Launched struct {
+	// Servers by endpoint, and the muxes of their listeners by port
+	servers map[string]*launchedServer
+	muxes   map[int]*handshake.Mux
+}
+
+type launchedServer struct {
+	mux        *handshake.Mux
+	serverName string
+	summary    <-chan handshake.ServeSummary
+}
+
+func newLaunched() *Launched {
+	return &Launched{servers: make(map[string]*launchedServer), muxes: make(map[int]*handshake.Mux)}
+}
+
+// Reports whether the handshake servers at port already have a listener
+func (l *Launched) listening(port int) bool {
+	_, ok := l.muxes[port]
+	return ok
+}
+
+// Starts the measuring loop of the handshake server s. The first server at a port accepts the connections of
+// ln, which are shared with the following servers at the port, selected by their server name (SNI).
+func (l *Launched) serve(ctx context.Context, s Server, ln net.Listener, serverConfig *tls.Config, opts *handshake.Options, target int) {
+	mux, ok := l.muxes[s.Port]
+	if !ok {
+		mux = handshake.NewMux(ln)
+		mux.Concurrent = opts.Concurrent
+		l.muxes[s.Port] = mux
+	}
+
+	l.servers[s.Endpoint()] = &launchedServer{
+		mux:        mux,
+		serverName: s.ServerName,
+		summary:    mux.Handle(s.ServerName, serverConfig, opts, target),
+	}
+
+	if !ok {
+		go mux.Serve(ctx)
+	}
+}
+
+// Stops the handshake server s, returning its summary once its partial results are saved. It reports false if
+// there is no such server, such as an HTTPS server.
+func (l *Launched) Stop(s Server) (handshake.ServeSummary, bool) {
+	ls, ok := l.servers[s.Endpoint()]
+	if !ok {
+		return handshake.ServeSummary{}, false
+	}
+	delete(l.servers, s.Endpoint())
+
+	ls.mux.Stop(ls.serverName)
+	return <-ls.summary, true
+}
+
+// Stops the handshake server s as Stop, discarding its results not saved yet
+func (l *Launched) Discard(s Server) (handshake.ServeSummary, bool) {
+	ls, ok := l.servers[s.Endpoint()]
+	if !ok {
+		return handshake.ServeSummary{}, false
+	}
+	delete(l.servers, s.Endpoint())
+
+	ls.mux.Discard(ls.serverName)
+	return <-ls.summary, true
+}
+
+// Stops all the handshake servers, returning their summaries by endpoint
+func (l *Launched) StopAll() map[string]handshake.ServeSummary {
+	summaries := make(map[string]handshake.ServeSummary)
+	for endpoint, ls := range l.servers {
+		ls.mux.Stop(ls.serverName)
+		summaries[endpoint] = <-ls.summary
+		delete(l.servers, endpoint)
+	}
+	return summaries
+}
+
+// Waits until all the handshake servers stop on their own, returning their summaries by endpoint
+func (l *Launched) Wait() map[string]handshake.ServeSummary {
+	summaries := make(map[string]handshake.ServeSummary)
+	for endpoint, ls := range l.servers {
+		summaries[endpoint] = <-ls.summary
+		delete(l.servers, endpoint)
+	}
+	return summaries
+}
+
+// wrapper function to start a server in each port
+func (l *Launched) startServerHybrid(ctx context.Context, cfg *Config, serverConfig *tls.Config, s Server, target int) error {
+	port := strconv.Itoa(s.Port)
+
+	if cfg.HTTP {
+		if cfg.CachedCert {
+			portTemp := strconv.Itoa(s.Port + 1)
+
+			go loadtest.LaunchTempServer(serverConfig, portTemp)
+		}
+		return loadtest.LaunchHTTPSServer(serverConfig, port, cfg.Link)
+	}
+
+	var ln net.Listener
+	if !l.listening(s.Port) {
+		var err error
+		ln, err = net.Listen("tcp", net.JoinHostPort("0.0.0.0", port))
+		if err != nil {
+			return err
+		}
+		ln = cfg.Link.Listener(ln)
+	}
+
+	l.serve(ctx, s, ln, serverConfig, &cfg.Options, target)
+	return nil
+}
+
+// A server of an experiment, measuring the handshakes of a pair of algorithms. The servers launched by the
+// server host are published to the client as a manifest, with the fingerprint of their certificate.
+type Server struct {
+	Port        int            `json:"port"`
+	ServerName  string         `json:"serverName,omitempty"`
+	KEX         string         `json:"kex"`
+	Auth        string         `json:"auth"`
+	Mode        handshake.Mode `json:"mode"`
+	Fingerprint string         `json:"fingerprint,omitempty"`
+}
+
+// Address of the server, its server name (SNI mode) and port, which identifies it among the servers of an
+// experiment
+func (s Server) Endpoint() string {
+	return net.JoinHostPort(s.ServerName, strconv.Itoa(s.Port))
+}
+
+// Error of the experiments without any pair of algorithms to be tested
+var ErrNoPairs = errors.New("no pair of algorithms in the same security level, see -crosslevel and -pairs")
+
+// Plans a server for each combination of keysKEX and keysAuth in the same security level and class, assigning ports
+// sequentially from firstPort. In KEMTLS, the authentication algorithm follows the key exchange one. With
+// cfg.CrossLevel, the combinations in different levels are planned too, and in KEMTLS each key exchange is
+// authenticated by each of the selected KEMs. If cfg.Pairs is set, exactly those pairs are planned instead. In
+// SNI mode, all the servers are planned at firstPort, each one with its own server name.
+func Plan(cfg *Config, keysKEX, keysAuth []string, firstPort int) []Server {
+	servers, _ := plan(cfg, keysKEX, keysAuth, firstPort)
+	return servers
+}
+
+// Prints the combinations of keysKEX and keysAuth that Plan skips because their security levels differ
+func ReportSkipped(cfg *Config, keysKEX, keysAuth []string) {
+	_, skipped := plan(cfg, keysKEX, keysAuth, 0)
+	if len(skipped) == 0 {
+		return
+	}
+
+	var pairs []string
+	for _, pair := range skipped {
+		pairs = append(pairs, pair.KEX+"/"+pair.Auth)
+	}
+	fmt.Printf("Skipping %d combinations in different security levels (see -crosslevel): %s\n\n", len(skipped), strings.Join(pairs, ", "))
+}
+
+// Plans the servers as Plan, also returning the combinations skipped because their security levels differ
+func plan(cfg *Config, keysKEX, keysAuth []string, firstPort int) (servers []Server, skipped []AlgorithmPair) {
+	port := firstPort
+
+	add := func(k, kAuth string) {
+		if cfg.SNI {
+			servers = append(servers, Server{Port: firstPort, ServerName: handshake.ServerName(k, kAuth, cfg.Mode), KEX: k, Auth: kAuth, Mode: cfg.Mode})
+			return
+		}
+		servers = append(servers, Server{Port: port, KEX: k, Auth: kAuth, Mode: cfg.Mode})
+		port = cfg.NextServerPort(port)
+	}
+
+	// Reports whether the pair is planned in its security levels
+	levels := func(k, kAuth string) bool {
+		if cfg.CrossLevel || algorithms.SecurityLevel(k) == algorithms.SecurityLevel(kAuth) {
+			return true
+		}
+		skipped = append(skipped, AlgorithmPair{KEX: k, Auth: kAuth})
+		return false
+	}
+
+	if len(cfg.Pairs) > 0 {
+		for _, pair := range cfg.Pairs {
+			add(pair.KEX, pair.Auth)
+		}
+		return servers, nil
+	}
+
+	if cfg.Mode == handshake.KEMTLS {
+		for _, k := range keysKEX {
+			if cfg.CrossLevel && !cfg.ClassicMcEliece && !(cfg.HTTP && cfg.Auth != "") {
+				for _, kAuth := range keysKEX {
+					if algorithms.ClassOf(k) == algorithms.ClassOf(kAuth) {
+						add(k, kAuth)
+					}
+				}
+				continue
+			}
+
+			var kAuth string
+
+			if cfg.ClassicMcEliece {
+				kAuth = algorithms.ClassicMcEliece(k)
+			} else if cfg.HTTP && cfg.Auth != "" {
+				kAuth = cfg.Auth
+			} else {
+				kAuth = k
+			}
+
+			if !levels(k, kAuth) {
+				continue
+			}
+			add(k, kAuth)
+		}
+	} else {
+		for _, kAuth := range keysAuth {
+			for _, k := range keysKEX {
+				// Hybrid key exchanges are authenticated by hybrid signatures, and pure ones by pure ones
+				if algorithms.ClassOf(k) != algorithms.ClassOf(kAuth) {
+					continue
+				}
+				if !levels(k, kAuth) {
+					continue
+				}
+				add(k, kAuth)
+			}
+		}
+	}
+
+	return servers, skipped
+}
+
+// Returns the port following the ones of the planned servers
+func NextPort(cfg *Config, servers []Server, firstPort int) int {
+	if len(servers) == 0 {
+		return firstPort
+	}
+	return cfg.NextServerPort(servers[len(servers)-1].Port)
+}
+
+// Starts the planned servers, setting the fingerprint of their certificates. The handshake servers measure until
+// ctx is done, they are stopped, or they complete target successful handshakes, if target is positive. If a
+// server cannot be started, the ones already started are stopped.
+func LaunchServers(ctx context.Context, cfg *Config, servers []Server, target int) (_ *Launched, err error) {
+	launched := newLaunched()
+	defer func() {
+		if err != nil {
+			launched.StopAll()
+		}
+	}()
+
+	if !cfg.HTTP {
+		if cfg.Mode == handshake.KEMTLS {
+			stats.KEMTLSInitCSVServer(cfg.ResultsFiles())
+		} else {
+			stats.TLSInitCSVServer(cfg.ResultsFiles())
+		}
+	}
+
+	for i, s := range servers {
+		strport := fmt.Sprintf("%d", s.Port)
+
+		serverConfig, err := handshake.NewConfig(s.KEX, s.Auth, false, &cfg.Options)
+		if err != nil {
+			return nil, fmt.Errorf("%s server %s/%s: %v", cfg.Mode, s.KEX, s.Auth, err)
+		}
+		if serverConfig == nil {
+			return nil, fmt.Errorf("%s server %s/%s: algorithms in different security levels", cfg.Mode, s.KEX, s.Auth)
+		}
+		servers[i].Fingerprint = handshake.Fingerprint(serverConfig.Certificates[0].Certificate[0])
+
+		address := cfg.ServerIP + ":" + strport
+		if s.ServerName != "" {
+			address += " (" + s.ServerName + ")"
+		}
+
+		//start
+		if cfg.Mode == handshake.KEMTLS {
+			fmt.Printf("Starting Hybrid KEMTLS server at %s  |  KEX: %s  Auth: %s\n", address, s.KEX, s.Auth)
+		} else {
+			fmt.Printf("Starting %s server at %s  |  KEX: %s  Auth: %s\n", cfg.ModeName(), address, s.KEX, s.Auth)
+		}
+
+		if err := launched.startServerHybrid(ctx, cfg, serverConfig, s, target); err != nil {
+			return nil, fmt.Errorf("%s server %s/%s: %v", cfg.Mode, s.KEX, s.Auth, err)
+		}
+	}
+
+	return launched, nil
+}
+
+// Name of the TLS mode of the servers launched by LaunchServers, other than KEMTLS
+func (cfg *Config) ModeName() string {
+	if cfg.Mode == handshake.Classic {
+		return "Classic TLS"
+	}
+	return "Hybrid PQTLS"
+}
+
+// Returns the port of the server launched after the one at port. HTTP servers in cached certificate mode also
+// occupy the following port with the temporary server used to retrieve the certificate.
+func (cfg *Config) NextServerPort(port int) int {
+	if cfg.HTTP && cfg.CachedCert {
+		return port + 2
+	}
+	return port + 1
+}
+
+// Signals the start and the end of the measurements of each server to the peer. The servers given up by the
+// client end with Discard instead of Stop, so that their results are not saved.
+type Progress interface {
+	Start(s Server) error
+	Stop(s Server) error
+	Discard(s Server) error
+}
+
+// Performs the handshakes with the servers of a verified manifest, saving the results and printing their
+// statistics. Each server is measured in cfg.Handshakes successful handshakes or, if cfg.Precision is set, until
+// its timings are precise enough, after cfg.Warmup discarded ones, and its certificate must match the fingerprint
+// of the manifest. The servers are measured one after the other or, if cfg.Interleave is set, in rounds of one
+// handshake with each server, in a random order seeded by cfg.Seed. If progress is not nil, it is signaled around
+// the measurements of each server. The servers given up after cfg.MaxFailures failed handshakes are reported in a
+// *FailedServersError, once the other servers are measured.
+func RunClientHandshakes(cfg *Config, servers []Server, progress Progress) error {
+	files := cfg.ResultsFiles()
+
+	//prepare output file
+	if cfg.Mode == handshake.KEMTLS {
+		stats.KEMTLSInitCSV(files)
+	} else {
+		stats.TLSInitCSV(files)
+	}
+	stats.InitFlightsCSV(files)
+	stats.InitSummaryCSV(files)
+
+	initCwnd := cfg.InitCwnd
+	if initCwnd == 0 {
+		initCwnd = netem.DefaultInitCwnd
+	}
+	initCwnd *= netem.MSS
+
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	// The resources of the handshakes are accounted for the whole process, so they are only measured when the
+	// handshakes are performed one at a time, and left empty for the ones that overlap another handshake of the
+	// process, such as the server side of a self test
+	opts := cfg.Options
+	opts.Resources = workers == 1
+
+	results := &clientResults{cfg: cfg, files: files, initCwnd: initCwnd, workers: workers}
+	var failed []Server
+
+	if cfg.Interleave {
+		// The seed is recorded in the flights file, so that the order of the handshakes can be reproduced
+		if cfg.Seed == 0 {
+			cfg.Seed = time.Now().UnixNano()
+		}
+		files.Seed = fmt.Sprintf("%d", cfg.Seed)
+		results.files = files
+		fmt.Printf("Interleaving the handshakes of %d servers in a random order, seed %d\n", len(servers), cfg.Seed)
+
+		var runs []*clientRun
+		for _, s := range servers {
+			r, err := newClientRun(cfg, s, &opts, progress)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, r)
+		}
+
+		if err := interleave(cfg, runs, &opts); err != nil {
+			return err
+		}
+
+		for _, r := range runs {
+			if reason := r.skipped(cfg); reason != "" {
+				fmt.Printf("Giving up KEX: %s  Auth: %s %s\n", r.s.KEX, r.s.Auth, reason)
+				failed = append(failed, r.s)
+				if progress != nil {
+					if err := progress.Discard(r.s); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := results.add(r, progress); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, s := range servers {
+			r, err := newClientRun(cfg, s, &opts, progress)
+			if err != nil {
+				return err
+			}
+
+			if err := r.runWorkers(cfg, &opts, workers); err != nil {
+				return err
+			}
+
+			if reason := r.skipped(cfg); reason != "" {
+				fmt.Printf("Giving up KEX: %s  Auth: %s %s\n", s.KEX, s.Auth, reason)
+				failed = append(failed, s)
+				if progress != nil {
+					if err := progress.Discard(r.s); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := results.add(r, progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	results.print()
+	fmt.Println("End of test.")
+
+	if len(failed) > 0 {
+		return &FailedServersError{Servers: failed}
+	}
+	return nil
+}
+
+// Client handshakes of a server, and its measurements
+type clientRun struct {
+	s            Server
+	port         string
+	clientConfig *tls.Config
+
+	// The full and the resumed handshakes are saved separately
+	full, resumed *clientTimings
+
+	// The certificate is checked in the first handshake that carries it, which is the first connection in
+	// cached certificate mode
+	verified bool
+
+	// Successful handshakes still to be discarded as warmup, and consecutive failed handshakes
+	warmup   int
+	failures int
+
+	// Start of the handshakes, which the flights of the handshakes are timed from
+	since time.Time
+}
+
+// Prepares the client handshakes of a server, signaling their start to progress. In cached certificate mode, the
+// first connection retrieves the certificate of the server.
+func newClientRun(cfg *Config, s Server, opts *handshake.Options, progress Progress) (*clientRun, error) {
+	k, kAuth := s.KEX, s.Auth
+
+	clientConfig, err := handshake.NewConfig(k, kAuth, true, &cfg.Options)
+	if err != nil {
+		return nil, fmt.Errorf("%s client %s/%s: %v", cfg.Mode, k, kAuth, err)
+	}
+	if clientConfig == nil {
+		return nil, fmt.Errorf("%s client %s/%s: algorithms in different security levels", cfg.Mode, k, kAuth)
+	}
+
+	if progress != nil {
+		if err := progress.Start(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Mode == handshake.KEMTLS {
+		fmt.Printf("Starting KEMTLS Handshakes: KEX: %s  Auth: %s\n", k, kAuth)
+	} else {
+		fmt.Printf("Starting TLS Handshakes: KEX Algorithm: %s - Auth Algorithm: %s \n", k, kAuth)
+	}
+
+	r := &clientRun{
+		s:            s,
+		port:         fmt.Sprintf("%d", s.Port),
+		clientConfig: clientConfig,
+		full:         &clientTimings{},
+		resumed:      &clientTimings{},
+		warmup:       cfg.Warmup,
+	}
+
+	if cfg.CachedCert {
+		result, err := handshake.Dial(clientConfig, opts, opts.ServerIP, r.port)
+		if err != nil {
+			return nil, fmt.Errorf("first connection for cached certificate mode at port %d: %v", s.Port, err)
+		}
+		if err := s.verifyCertificate(result.State); err != nil {
+			return nil, err
+		}
+		r.verified = true
+		clientConfig.CachedCert = result.State.CertificateMessage
+	}
+
+	r.since = time.Now()
+	return r, nil
+}
+
+// Reports whether the handshakes of the server are all measured
+func (r *clientRun) done(cfg *Config) bool {
+	n := len(r.full.fullProtocol)
+	if cfg.Precision <= 0 {
+		return n >= cfg.Handshakes
+	}
+	if n >= cfg.MaxHandshakes {
+		return true
+	}
+	return n >= cfg.Handshakes && r.halfWidth(cfg) <= cfg.Precision
+}
+
+// Relative half-width of the 95% confidence interval of the mean full protocol time of the full handshakes,
+// without their outliers if cfg.RejectOutliers is set
+func (r *clientRun) halfWidth(cfg *Config) float64 {
+	measurements := r.full.fullProtocol
+	if cfg.RejectOutliers {
+		measurements, _ = stats.RejectOutliers(measurements)
+	}
+	return stats.RelativeHalfWidth(measurements)
+}
+
+// Reports whether the server is given up after cfg.MaxFailures consecutive failed handshakes
+func (r *clientRun) gaveUp(cfg *Config) bool {
+	return cfg.MaxFailures > 0 && r.failures >= cfg.MaxFailures
+}
+
+// Reason the measurements of the server are not saved, or "" if they are: the server was given up, or, with
+// cfg.Precision, too few handshakes were measured for a confidence interval
+func (r *clientRun) skipped(cfg *Config) string {
+	if r.gaveUp(cfg) {
+		return fmt.Sprintf("after %d failed handshakes", r.failures)
+	}
+	if n := len(r.full.fullProtocol); n == 0 || (cfg.Precision > 0 && n < 2) {
+		return fmt.Sprintf("with %d measured handshakes, too few to summarize", n)
+	}
+	return ""
+}
+
+// Performs a full handshake with the server, followed by a handshake resuming its session if cfg.Resumption is
+// set, as worker in the given interleaved round, if any
+func (r *clientRun) dial(cfg *Config, opts *handshake.Options, clientConfig *tls.Config, worker, round int) (full, resumed handshake.Result, err error) {
+	// Each full handshake starts a new session, resumed by the following handshake
+	if cfg.Resumption {
+		clientConfig.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+	}
+
+	full, err = r.dialWorker(clientConfig, opts, worker, round)
+	if err == nil && full.Success && cfg.Resumption {
+		resumed, err = r.dialWorker(clientConfig, opts, worker, round)
+	}
+	return full, resumed, err
+}
+
+// Dials the server as a worker, recording the worker, the interleaved round and the start and end of the
+// connection, since the start of the handshakes of the server, in the flights of the result
+func (r *clientRun) dialWorker(clientConfig *tls.Config, opts *handshake.Options, worker, round int) (handshake.Result, error) {
+	start := time.Now()
+	result, err := handshake.Dial(clientConfig, opts, opts.ServerIP, r.port)
+
+	result.Flights.Worker = worker
+	result.Flights.Round = round
+	result.Flights.Start = float64(start.Sub(r.since)) / float64(time.Millisecond)
+	result.Flights.End = float64(time.Since(r.since)) / float64(time.Millisecond)
+	return result, err
+}
+
+// Records the handshakes performed by dial, returning whether they succeeded. The successful handshakes are
+// discarded while warming up, and measured afterwards. A fatal error stops the experiment.
+func (r *clientRun) record(cfg *Config, full, resumed handshake.Result, err error) (bool, error) {
+	if err != nil || !full.Success || (cfg.Resumption && !resumed.Success) {
+		r.failures++
+		return false, nil
+	}
+	r.failures = 0
+
+	if cfg.Resumption && !resumed.State.DidResume {
+		return false, fmt.Errorf("%s server %s/%s at port %d did not resume the session", cfg.Mode, r.s.KEX, r.s.Auth, r.s.Port)
+	}
+	if !r.verified {
+		if err := r.s.verifyCertificate(full.State); err != nil {
+			return false, err
+		}
+		r.verified = true
+	}
+
+	if r.warmup > 0 {
+		r.warmup--
+		return true, nil
+	}
+
+	r.full.add(full)
+	if cfg.Resumption {
+		r.resumed.add(resumed)
+	}
+	return true, nil
+}
+
+// Performs the handshakes with the server from workers goroutines, each one with its own configuration, until
+// the warmup and the measured handshakes succeed. The failed handshakes are retried, and the consecutive ones
+// counted. With cfg.Precision, the handshakes in progress once the timings are precise enough are measured too.
+func (r *clientRun) runWorkers(cfg *Config, opts *handshake.Options, workers int) error {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		remaining = r.warmup + cfg.Handshakes
+		fatal     error
+	)
+	if cfg.Precision > 0 {
+		remaining = r.warmup + cfg.MaxHandshakes
+	}
+
+	for w := 1; w <= workers; w++ {
+		workerConfig := r.clientConfig
+		if workers > 1 {
+			workerConfig = r.clientConfig.Clone()
+		}
+
+		wg.Add(1)
+		go func(worker int, workerConfig *tls.Config) {
+			defer wg.Done()
+
+			for {
+				mu.Lock()
+				if remaining == 0 || fatal != nil || r.gaveUp(cfg) {
+					mu.Unlock()
+					return
+				}
+				remaining--
+				mu.Unlock()
+
+				full, resumed, err := r.dial(cfg, opts, workerConfig, worker, 0)
+
+				mu.Lock()
+				ok, err := r.record(cfg, full, resumed, err)
+				if err != nil {
+					fatal = err
+				} else if !ok {
+					remaining++ //do not count this handshake timing
+				}
+				if r.warmup == 0 && r.done(cfg) {
+					remaining = 0
+				}
+				mu.Unlock()
+			}
+		}(w, workerConfig)
+	}
+	wg.Wait()
+
+	return fatal
+}
+
+// Performs the handshakes with the servers in rounds, each one a handshake with each server still measuring, in
+// a random order seeded by cfg.Seed, until the warmup and the measured handshakes of every server succeed or it
+// is given up. The failed handshakes are retried in the next rounds.
+func interleave(cfg *Config, runs []*clientRun, opts *handshake.Options) error {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	for round := 1; ; round++ {
+		var pending []*clientRun
+		for _, r := range runs {
+			if !r.done(cfg) && !r.gaveUp(cfg) {
+				pending = append(pending, r)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		for _, i := range rng.Perm(len(pending)) {
+			r := pending[i]
+			full, resumed, err := r.dial(cfg, opts, r.clientConfig, 1, round)
+			if _, err := r.record(cfg, full, resumed, err); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Results of the client handshakes of the servers, printed once all the servers are measured
+type clientResults struct {
+	cfg      *Config
+	files    stats.Files
+	initCwnd int
+	workers  int
+
+	kemtlsResultsList, kemtlsResumedList []stats.KEMTLSClientResultsInfo
+	tlsResultsList, tlsResumedList       []stats.TLSClientResultsInfo
+	summaryList, resumedSummaryList      []stats.CombinationSummary
+}
+
+// Signals the end of the measurements of a server to progress, and saves them
+func (c *clientResults) add(r *clientRun, progress Progress) error {
+	cfg := c.cfg
+	k, kAuth := r.s.KEX, r.s.Auth
+	levels := handshake.Levels(k, kAuth)
+
+	if progress != nil {
+		if err := progress.Stop(r.s); err != nil {
+			return err
+		}
+	}
+
+	n := len(r.full.fullProtocol)
+	if cfg.Precision > 0 {
+		fmt.Printf("Handshakes: %d  95%% CI half-width: %.2f%% of the mean (target %.2f%%)\n", n, r.halfWidth(cfg)*100, cfg.Precision*100)
+	}
+
+	kinds := []*clientTimings{r.full}
+	if cfg.Resumption {
+		kinds = append(kinds, r.resumed)
+	}
+
+	for _, t := range kinds {
+		files := c.files
+		files.Handshake = stats.FullHandshake
+		if t == r.resumed {
+			files.Handshake = stats.ResumedHandshake
+			fmt.Print("Resumed handshakes | ")
+		}
+
+		stats.SaveFlightsCSV(files, k, kAuth, levels, t.flights, c.initCwnd)
+		if len(t.flights) > 0 {
+			printFlights(t.flights[len(t.flights)-1], c.initCwnd)
+		}
+		printLatencies(t.flights, c.workers)
+		if avg, measured := stats.AverageResources(t.resources); measured > 0 {
+			printResources(avg, measured, len(t.resources))
+		}
+
+		cconnState := t.lastState
+		handshakeSizes := make(map[string]uint32)
+		handshakeSizes["ClientHello"] = cconnState.ClientHandshakeSizes.ClientHello
+		handshakeSizes["Certificate"] = cconnState.ClientHandshakeSizes.Certificate
+		handshakeSizes["Finished"] = cconnState.ClientHandshakeSizes.Finished
+
+		//save results first
+		if cfg.Mode == handshake.KEMTLS {
+			handshakeSizes["ClientKEMCiphertext"] = cconnState.ClientHandshakeSizes.ClientKEMCiphertext
+
+			stats.SaveSummaryCSV(files, k, kAuth, levels, []stats.Series{
+				{Metric: "timingFullProtocol", Values: t.fullProtocol},
+				{Metric: "timingSendAppData", Values: t.sendAppData},
+				{Metric: "timingProcessServerHello", Values: t.processServerHello},
+				{Metric: "timingWriteClientHello", Values: t.writeClientHello},
+				{Metric: "timingWriteKEMCiphertext", Values: t.writeKEMCiphertext},
+			}, cfg.RejectOutliers)
+
+			stats.KEMTLSSaveCSV(files, t.fullProtocol, t.sendAppData, t.processServerHello, t.writeClientHello, t.writeKEMCiphertext, k, kAuth, levels, n, handshakeSizes, t.resources)
+
+			algoResults := stats.KEMTLSComputeStats(t.fullProtocol, t.sendAppData, t.processServerHello, t.writeClientHello, t.writeKEMCiphertext, n)
+			algoResults.KEXName = k
+			algoResults.AuthName = kAuth
+			if t == r.resumed {
+				c.kemtlsResumedList = append(c.kemtlsResumedList, algoResults)
+			} else {
+				c.kemtlsResultsList = append(c.kemtlsResultsList, algoResults)
+			}
+		} else {
+			handshakeSizes["CertificateVerify"] = cconnState.ClientHandshakeSizes.CertificateVerify
+
+			stats.SaveSummaryCSV(files, k, kAuth, levels, []stats.Series{
+				{Metric: "timingFullProtocol", Values: t.fullProtocol},
+				{Metric: "timingProcessServerHello", Values: t.processServerHello},
+				{Metric: "timingWriteClientHello", Values: t.writeClientHello},
+			}, cfg.RejectOutliers)
+
+			stats.TLSSaveCSV(files, t.fullProtocol, t.processServerHello, t.writeClientHello, k, kAuth, levels, n, handshakeSizes, t.resources)
+
+			algoResults := stats.TLSComputeStats(t.fullProtocol, t.processServerHello, t.writeClientHello, n)
+			algoResults.KEXName = k
+			algoResults.AuthName = kAuth
+			if t == r.resumed {
+				c.tlsResumedList = append(c.tlsResumedList, algoResults)
+			} else {
+				c.tlsResultsList = append(c.tlsResultsList, algoResults)
+			}
+		}
+
+		summary := stats.CombinationSummary{KEXName: k, AuthName: kAuth, Summary: stats.Summarize(t.fullProtocol, cfg.RejectOutliers)}
+		if t == r.resumed {
+			c.resumedSummaryList = append(c.resumedSummaryList, summary)
+		} else {
+			c.summaryList = append(c.summaryList, summary)
+		}
+	}
+	return nil
+}
+
+// Prints the statistics of the servers
+func (c *clientResults) print() {
+	if c.cfg.Mode == handshake.KEMTLS {
+		stats.KEMTLSPrintStatistics(c.kemtlsResultsList)
+	} else {
+		stats.TLSPrintStatistics(c.tlsResultsList)
+	}
+	fmt.Println()
+	stats.PrintSummaries("timingFullProtocol", c.summaryList)
+	if c.cfg.Resumption {
+		fmt.Printf("\nResumed handshakes:\n\n")
+		if c.cfg.Mode == handshake.KEMTLS {
+			stats.KEMTLSPrintStatistics(c.kemtlsResumedList)
+		} else {
+			stats.TLSPrintStatistics(c.tlsResumedList)
+		}
+		fmt.Println()
+		stats.PrintSummaries("timingFullProtocol", c.resumedSummaryList)
+	}
+}
+
+// Client timings of the handshakes of a server, full or resumed
+type clientTimings struct {
+	fullProtocol       []float64
+	sendAppData        []float64
+	processServerHello []float64
+	writeClientHello   []float64
+	writeKEMCiphertext []float64
+	flights            []stats.HandshakeFlights
+	resources          []stats.Resources
+
+	lastState tls.ConnectionState
+}
+
+func (t *clientTimings) add(result handshake.Result) {
+	timingState := result.Timing
+
+	t.fullProtocol = append(t.fullProtocol, float64(timingState.Client.FullProtocol)/float64(time.Millisecond))
+	t.sendAppData = append(t.sendAppData, float64(timingState.Client.SendAppData)/float64(time.Millisecond))
+	t.processServerHello = append(t.processServerHello, float64(timingState.Client.ProcessServerHello)/float64(time.Millisecond))
+	t.writeClientHello = append(t.writeClientHello, float64(timingState.Client.WriteClientHello)/float64(time.Millisecond))
+	t.writeKEMCiphertext = append(t.writeKEMCiphertext, float64(timingState.Client.WriteKEMCiphertext)/float64(time.Millisecond))
+	t.flights = append(t.flights, result.Flights)
+	if result.Resources != nil {
+		t.resources = append(t.resources, *result.Resources)
+	}
+	t.lastState = result.State
+}
+
+// Prints the throughput and the latency distribution of the client handshakes of a server
+func printLatencies(flights []stats.HandshakeFlights, workers int) {
+	latencies := stats.Latencies(flights)
+	fmt.Printf("Workers: %d  Throughput: %.1f handshakes/s  Latency: min %.3f ms, p50 %.3f ms, p90 %.3f ms, p99 %.3f ms, max %.3f ms\n",
+		workers, stats.Throughput(flights), stats.Percentile(latencies, 0), stats.Percentile(latencies, 50),
+		stats.Percentile(latencies, 90), stats.Percentile(latencies, 99), stats.Percentile(latencies, 100))
+}
+
+// Prints the average CPU time and heap allocations of the client handshakes of a server that did not overlap
+// another handshake of the process
+func printResources(r stats.Resources, measured, handshakes int) {
+	fmt.Printf("CPU: user %.3f ms, system %.3f ms  Allocations: %d (%d bytes) per handshake, over %d of %d handshakes\n",
+		r.UserCPU, r.SystemCPU, r.Allocs, r.AllocBytes, measured, handshakes)
+}
+
+// Prints the flights of a client handshake
+func printFlights(f stats.HandshakeFlights, initCwnd int) {
+	fmt.Printf("Flights: %d (%s)  Round trips: %d  Server first flight: %d bytes  Time to first byte: %.3f ms",
+		len(f.Flights), f.BytesString(), f.RoundTrips, f.ServerFirstFlight, f.TimeToFirstByte)
+	if f.HelloRetryRequest {
+		fmt.Print(", HelloRetryRequest")
+	}
+	if f.ExceedsInitCwnd(initCwnd) {
+		fmt.Printf(", exceeds the initial congestion window of %d bytes", initCwnd)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,532 @@
+package experiment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/handshake"
+	"tls_tests/src/loadtest"
+	"tls_tests/src/netem"
+	"tls_tests/src/pki"
+)
+
+// Experiment spec file. Fields set at the top level are the defaults of every experiment.
+type Spec struct {
+	ServerIP   string `json:"serverIP"`
+	ClientIP   string `json:"clientIP"`
+	HybridRoot string `json:"hybridRoot"`
+	RootCert   string `json:"rootCert"`
+	RootKey    string `json:"rootKey"`
+	RootLevel  int    `json:"rootLevel"`
+	Handshakes int    `json:"handshakes"`
+	ClientAuth bool   `json:"clientAuth"`
+	FirstPort  int    `json:"firstPort"`
+
+	// Emulated link of the experiments, as in -link
+	Link string `json:"link"`
+
+	Experiments []Experiment `json:"experiments"`
+}
+
+type Experiment struct {
+	Name            string `json:"name"`
+	Disabled        bool   `json:"disabled"`
+	Mode            string `json:"mode"` // kemtls, pqtls or tls (classic)
+	CachedCert      bool   `json:"cachedCert"`
+	ClassicMcEliece bool   `json:"classicMcEliece"`
+	ClientAuth      *bool  `json:"clientAuth"`
+	Handshakes      int    `json:"handshakes"`
+
+	// Algorithm matrix of the handshake experiments, selected by name, family or level as in -kexlist and
+	// -authlist. If empty, the default test algorithms are used
+	KEX  []string `json:"kex"`
+	Auth []string `json:"auth"`
+
+	// Class of the post-quantum algorithms of the matrix: hybrid (the default), pure or both
+	Class string `json:"class"`
+
+	// Also test the combinations of the matrix in different security levels, as in -crosslevel
+	CrossLevel bool `json:"crossLevel"`
+
+	// Pairs of algorithms to be tested instead of the matrix, in any security level, as in -pairs
+	Pairs []AlgorithmPair `json:"pairs"`
+
+	// Follow each full handshake by a resumed one, as in -resumption
+	Resumption bool `json:"resumption"`
+
+	// Key exchange of the initial key share of the client, as in -keyshare
+	KeyShare string `json:"keyShare"`
+
+	// Serve all the combinations at a single port, selected by server name, as in -sni
+	SNI bool `json:"sni"`
+
+	// Handle the connections of the servers concurrently, as in -concurrent
+	Concurrent bool `json:"concurrent"`
+
+	// Goroutines performing the client handshakes of each server concurrently, as in -workers
+	Workers int `json:"workers"`
+
+	// Reject the outliers of the timings before summarizing their distributions, as in -outliers
+	RejectOutliers bool `json:"rejectOutliers"`
+
+	// Discarded handshakes of each kind performed with each server before the measured ones, as in -warmup
+	Warmup int `json:"warmup"`
+
+	// Interleave the handshakes of the servers in a random order, as in -interleave and -seed
+	Interleave bool  `json:"interleave"`
+	Seed       int64 `json:"seed"`
+
+	// Measure each server until its timings are precise enough, as in -precision (in percent) and -maxhandshakes
+	Precision     float64 `json:"precision"`
+	MaxHandshakes int     `json:"maxHandshakes"`
+
+	// Intermediate CAs of the certificate chain, as in -chaindepth and -intermediates
+	ChainDepth    *int     `json:"chainDepth"`
+	Intermediates []string `json:"intermediates"`
+
+	// Emulated link of the experiment, overriding the one of the spec
+	Link string `json:"link"`
+
+	// If present, the experiment is an HTTP load test instead of a handshake experiment
+	LoadTest *LoadTestSpec `json:"loadTest"`
+}
+
+type LoadTestSpec struct {
+	Clients   []int           `json:"clients"`
+	Seconds   int64           `json:"seconds"`
+	KeepAlive *bool           `json:"keepAlive"`
+	Pairs     []AlgorithmPair `json:"pairs"`
+}
+
+type AlgorithmPair struct {
+	KEX  string `json:"kex"`
+	Auth string `json:"auth"`
+}
+
+// A single run expanded from an experiment: either a whole handshake matrix or one load test
+type Run struct {
+	name            string
+	mode            handshake.Mode
+	cachedCert      bool
+	classicMcEliece bool
+	clientAuth      bool
+	handshakes      int
+	keysKEX         []string
+	keysAuth        []string
+	crossLevel      bool
+	pairs           []AlgorithmPair
+	chain           pki.ChainShape
+	link            netem.Profile
+	resumption      bool
+	keyShare        string
+	sni             bool
+	concurrent      bool
+	workers         int
+	rejectOutliers  bool
+	warmup          int
+	interleave      bool
+	seed            int64
+	precision       float64
+	maxHandshakes   int
+
+	isLoadTest bool
+	clients    int
+	seconds    int64
+	keepAlive  bool
+
+	// Set in the first load test of an experiment, whose results file is cleaned before it starts
+	cleanResults bool
+}
+
+func ReadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{FirstPort: 4433, Handshakes: 1}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if spec.ServerIP == "" || spec.ClientIP == "" {
+		return nil, errors.New("serverIP and clientIP must be set in the spec file")
+	}
+	if spec.HybridRoot == "" && spec.RootCert == "" {
+		return nil, errors.New("hybridRoot or rootCert must be set in the spec file")
+	}
+	if spec.RootLevel != 0 && (spec.RootCert != "" || (spec.RootLevel != 1 && spec.RootLevel != 3 && spec.RootLevel != 5)) {
+		return nil, errors.New("rootLevel must be 1, 3 or 5, and only applies to hybridRoot")
+	}
+	if _, err := netem.Parse(spec.Link); err != nil {
+		return nil, fmt.Errorf("invalid link in the spec file: %v", err)
+	}
+
+	return spec, nil
+}
+
+// Expands the enabled experiments of the spec into the ordered list of runs performed by both the client
+// and the server. Both sides must expand the same spec file, so that the port numbering matches.
+func (spec *Spec) Expand() ([]Run, error) {
+	var runs []Run
+
+	for _, e := range spec.Experiments {
+		if e.Disabled {
+			continue
+		}
+
+		base := Run{
+			name:            e.Name,
+			cachedCert:      e.CachedCert,
+			classicMcEliece: e.ClassicMcEliece,
+			clientAuth:      spec.ClientAuth,
+			handshakes:      spec.Handshakes,
+			resumption:      e.Resumption,
+			keyShare:        e.KeyShare,
+			sni:             e.SNI,
+			concurrent:      e.Concurrent,
+			workers:         e.Workers,
+			rejectOutliers:  e.RejectOutliers,
+			warmup:          e.Warmup,
+			interleave:      e.Interleave,
+			seed:            e.Seed,
+			precision:       e.Precision / 100,
+			maxHandshakes:   e.MaxHandshakes,
+		}
+
+		mode, err := handshake.ParseMode(e.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("experiment %q: %v", e.Name, err)
+		}
+		base.mode = mode
+
+		if e.ClassicMcEliece && mode != handshake.KEMTLS {
+			return nil, fmt.Errorf("experiment %q: classicMcEliece only applies to the kemtls mode", e.Name)
+		}
+
+		if e.ClientAuth != nil {
+			base.clientAuth = *e.ClientAuth
+		}
+		if e.Handshakes > 0 {
+			base.handshakes = e.Handshakes
+		}
+
+		base.chain = pki.ChainShape{Depth: pki.DefaultChainDepth, Algorithms: e.Intermediates}
+		if e.ChainDepth != nil {
+			base.chain.Depth = *e.ChainDepth
+		}
+		if err := base.chain.Check(base.mode == handshake.Classic); err != nil {
+			return nil, fmt.Errorf("experiment %q: invalid chainDepth or intermediates: %v", e.Name, err)
+		}
+
+		link := spec.Link
+		if e.Link != "" {
+			link = e.Link
+		}
+		base.link, err = netem.Parse(link)
+		if err != nil {
+			return nil, fmt.Errorf("experiment %q: invalid link: %v", e.Name, err)
+		}
+
+		if e.Workers < 0 || e.Warmup < 0 {
+			return nil, fmt.Errorf("experiment %q: workers and warmup must not be negative", e.Name)
+		}
+		if e.Seed != 0 && !e.Interleave {
+			return nil, fmt.Errorf("experiment %q: seed only applies to interleave", e.Name)
+		}
+		if e.Interleave && e.Workers > 1 {
+			return nil, fmt.Errorf("experiment %q: interleave does not apply to workers", e.Name)
+		}
+		if e.Precision < 0 || (e.Precision == 0 && e.MaxHandshakes != 0) {
+			return nil, fmt.Errorf("experiment %q: maxHandshakes only applies to a positive precision", e.Name)
+		}
+		if e.Precision > 0 && base.handshakes < 2 {
+			return nil, fmt.Errorf("experiment %q: precision requires at least 2 handshakes, for a confidence interval", e.Name)
+		}
+		if e.Precision > 0 && e.MaxHandshakes < base.handshakes {
+			return nil, fmt.Errorf("experiment %q: precision requires maxHandshakes of at least handshakes (%d)", e.Name, base.handshakes)
+		}
+		if e.KeyShare != "" {
+			if _, err := algorithms.NameToCurveID(e.KeyShare); err != nil {
+				return nil, fmt.Errorf("experiment %q: unknown keyShare %q", e.Name, e.KeyShare)
+			}
+		}
+
+		if e.LoadTest == nil {
+			classes := []algorithms.Class{algorithms.Classic}
+			if base.mode != handshake.Classic {
+				class := e.Class
+				if class == "" {
+					class = "hybrid"
+				}
+				classes, err = algorithms.ParseClasses(class)
+				if err != nil {
+					return nil, fmt.Errorf("experiment %q: %v", e.Name, err)
+				}
+			} else if e.Class != "" {
+				return nil, fmt.Errorf("experiment %q: class does not apply to the tls mode", e.Name)
+			}
+
+			if len(e.Pairs) > 0 {
+				if len(e.KEX) > 0 || len(e.Auth) > 0 || e.Class != "" || e.CrossLevel {
+					return nil, fmt.Errorf("experiment %q: pairs are exclusive with kex, auth, class and crossLevel", e.Name)
+				}
+				if err := checkPairs(e.Pairs, base.mode); err != nil {
+					return nil, fmt.Errorf("experiment %q: %v", e.Name, err)
+				}
+				base.pairs = e.Pairs
+				base.crossLevel = true
+				base.keysKEX, base.keysAuth = PairAlgorithms(e.Pairs)
+				runs = append(runs, base)
+				continue
+			}
+
+			keysKEX, keysAuth, err := algorithms.SelectTests(strings.Join(e.KEX, ","), strings.Join(e.Auth, ","), classes...)
+			if err != nil {
+				return nil, fmt.Errorf("experiment %q: %v", e.Name, err)
+			}
+			base.keysKEX, base.keysAuth = keysKEX, keysAuth
+			base.crossLevel = e.CrossLevel
+			runs = append(runs, base)
+			continue
+		}
+
+		if len(e.Pairs) > 0 || e.CrossLevel {
+			return nil, fmt.Errorf("experiment %q: load tests take their pairs from loadTest", e.Name)
+		}
+		if e.Resumption || e.KeyShare != "" || e.SNI || e.Concurrent || e.Workers != 0 || e.RejectOutliers ||
+			e.Warmup != 0 || e.Interleave || e.Precision != 0 {
+			return nil, fmt.Errorf("experiment %q: resumption, keyShare, sni, concurrent, workers, rejectOutliers, warmup, interleave and precision do not apply to load tests", e.Name)
+		}
+
+		if len(e.LoadTest.Clients) == 0 || e.LoadTest.Seconds <= 0 || len(e.LoadTest.Pairs) == 0 {
+			return nil, fmt.Errorf("experiment %q: load tests require clients, seconds and pairs", e.Name)
+		}
+
+		firstRun := len(runs)
+		for _, numClients := range e.LoadTest.Clients {
+			for _, pair := range e.LoadTest.Pairs {
+				run := base
+				run.isLoadTest = true
+				run.clients = numClients
+				run.seconds = e.LoadTest.Seconds
+				run.keepAlive = e.LoadTest.KeepAlive == nil || *e.LoadTest.KeepAlive
+				run.keysKEX = []string{pair.KEX}
+				run.keysAuth = []string{pair.Auth}
+				run.cleanResults = len(runs) == firstRun
+
+				if err := checkAlgorithms(run.keysKEX, run.keysAuth, run.mode); err != nil {
+					return nil, fmt.Errorf("experiment %q: %v", e.Name, err)
+				}
+				runs = append(runs, run)
+			}
+		}
+	}
+
+	return runs, nil
+}
+
+// Checks that the algorithm names are known before any measurement starts
+func checkAlgorithms(keysKEX, keysAuth []string, mode handshake.Mode) error {
+	for _, k := range keysKEX {
+		if _, err := algorithms.NameToCurveID(k); err != nil {
+			return fmt.Errorf("%v: %s", err, k)
+		}
+	}
+	for _, a := range keysAuth {
+		var err error
+		switch mode {
+		case handshake.Classic:
+			_, err = algorithms.NameToClassicSigAlgo(a)
+		case handshake.PQTLS:
+			_, err = algorithms.NameToSigID(a)
+		default:
+			_, err = algorithms.NameToCurveID(a)
+		}
+		if err != nil {
+			return fmt.Errorf("%v: %s", err, a)
+		}
+	}
+	return nil
+}
+
+// Parses a comma separated list of pairs of algorithms, each one as KEX:Auth (e.g. X25519:ECDSA_P521), and
+// checks their names in mode
+func ParsePairs(list string, mode handshake.Mode) ([]AlgorithmPair, error) {
+	var pairs []AlgorithmPair
+	for _, field := range strings.Split(list, ",") {
+		names := strings.Split(strings.TrimSpace(field), ":")
+		if len(names) != 2 || names[0] == "" || names[1] == "" {
+			return nil, fmt.Errorf("pair %q is not KEX:Auth", field)
+		}
+		pairs = append(pairs, AlgorithmPair{KEX: names[0], Auth: names[1]})
+	}
+
+	if err := checkPairs(pairs, mode); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// Returns the key exchange and the authentication algorithms of the pairs, in the order they first appear
+func PairAlgorithms(pairs []AlgorithmPair) (keysKEX, keysAuth []string) {
+	seenKEX, seenAuth := make(map[string]bool), make(map[string]bool)
+	for _, pair := range pairs {
+		if !seenKEX[pair.KEX] {
+			seenKEX[pair.KEX] = true
+			keysKEX = append(keysKEX, pair.KEX)
+		}
+		if !seenAuth[pair.Auth] {
+			seenAuth[pair.Auth] = true
+			keysAuth = append(keysAuth, pair.Auth)
+		}
+	}
+	return keysKEX, keysAuth
+}
+
+// Checks that the algorithm names of the pairs are known in mode
+func checkPairs(pairs []AlgorithmPair, mode handshake.Mode) error {
+	keysKEX, keysAuth := PairAlgorithms(pairs)
+	return checkAlgorithms(keysKEX, keysAuth, mode)
+}
+
+// Returns the configuration of the servers and clients of the run
+func (run *Run) Config(spec *Spec) *Config {
+	cfg := &Config{
+		Options: handshake.Options{
+			Mode:            run.mode,
+			CachedCert:      run.cachedCert,
+			ClassicMcEliece: run.classicMcEliece,
+			ClientAuth:      run.clientAuth,
+			ServerIP:        spec.ServerIP,
+			ClientIP:        spec.ClientIP,
+			Root:            pki.RootCA{HybridFamily: spec.HybridRoot, CertFile: spec.RootCert, KeyFile: spec.RootKey, Level: spec.RootLevel},
+			Chain:           run.chain,
+			Link:            run.link,
+			Handshakes:      run.handshakes,
+			CrossLevel:      run.crossLevel,
+			Resumption:      run.resumption,
+			KeyShare:        run.keyShare,
+			SNI:             run.sni,
+			Concurrent:      run.concurrent,
+			Warmup:          run.warmup,
+		},
+		HTTP:           run.isLoadTest,
+		Pairs:          run.pairs,
+		Workers:        run.workers,
+		RejectOutliers: run.rejectOutliers,
+		Interleave:     run.interleave,
+		Seed:           run.seed,
+		Precision:      run.precision,
+		MaxHandshakes:  run.maxHandshakes,
+	}
+
+	if run.isLoadTest {
+		cfg.Auth = run.keysAuth[0]
+	}
+	return cfg
+}
+
+func (run *Run) String() string {
+	mode := run.mode.String()
+	if run.cachedCert {
+		mode += " (cached cert)"
+	}
+	if run.classicMcEliece {
+		mode += " (Classic McEliece)"
+	}
+	if run.resumption {
+		mode += " (resumption)"
+	}
+	if run.keyShare != "" {
+		mode += " (key share " + run.keyShare + ")"
+	}
+	if run.sni {
+		mode += " (SNI)"
+	}
+	if run.concurrent {
+		mode += " (concurrent)"
+	}
+	if run.workers > 1 {
+		mode += fmt.Sprintf(" (%d workers)", run.workers)
+	}
+	if run.rejectOutliers {
+		mode += " (outliers rejected)"
+	}
+	if run.warmup > 0 {
+		mode += fmt.Sprintf(" (%d warmup)", run.warmup)
+	}
+	if run.interleave {
+		mode += " (interleaved)"
+	}
+	if run.precision > 0 {
+		mode += fmt.Sprintf(" (precision %g%%, up to %d handshakes)", run.precision*100, run.maxHandshakes)
+	}
+	if run.link.Enabled() {
+		mode += " (link " + run.link.String() + ")"
+	}
+
+	if run.isLoadTest {
+		return fmt.Sprintf("%s: %s load test | KEX: %s  Auth: %s  Clients: %d  Seconds: %d", run.name, mode, run.keysKEX[0], run.keysAuth[0], run.clients, run.seconds)
+	}
+	if run.mode == handshake.KEMTLS {
+		// The authentication algorithms follow the key exchange ones
+		return fmt.Sprintf("%s: %s handshakes | KEX: %v  Handshakes: %d", run.name, mode, run.keysKEX, run.handshakes)
+	}
+	return fmt.Sprintf("%s: %s handshakes | KEX: %v  Auth: %v  Handshakes: %d", run.name, mode, run.keysKEX, run.keysAuth, run.handshakes)
+}
+
+// Negotiates each run with the control server at spec.ServerIP, performing its handshakes or its load test
+// against the servers launched for it. The control server is shut down once all runs are finished.
+func RunClient(spec *Spec, runs []Run) error {
+	ctl := DialControl(spec.ServerIP)
+
+	for i := range runs {
+		run := &runs[i]
+		cfg := run.Config(spec)
+
+		fmt.Printf("\nExperiment %s\n\n", run)
+
+		if err := run.perform(ctl, cfg, spec.FirstPort); err != nil {
+			if _, ok := err.(*PeerError); !ok {
+				ctl.ReportError(err)
+			}
+			return fmt.Errorf("experiment %s: %v", run.name, err)
+		}
+	}
+
+	return ctl.Shutdown()
+}
+
+func (run *Run) perform(ctl *ControlClient, cfg *Config, firstPort int) error {
+	servers, err := ctl.Negotiate(cfg, run.keysKEX, run.keysAuth, firstPort)
+	if err != nil {
+		return err
+	}
+
+	if !run.isLoadTest {
+		return RunClientHandshakes(cfg, servers, ctl)
+	}
+
+	if run.cleanResults {
+		os.Remove(loadtest.ResultsFileName(&cfg.Options))
+	}
+
+	s := servers[0]
+	if err := ctl.Start(s); err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://%s:%d", cfg.ServerIP, s.Port)
+	loadtest.Run(&cfg.Options, s.KEX, s.Auth, url, run.clients, run.seconds, run.keepAlive)
+
+	return ctl.Stop(s)
+}
@@ -0,0 +1,342 @@
+// Package handshake configures the Hybrid KEMTLS, Hybrid PQTLS and classic TLS clients and servers, and
+// measures their handshakes.
+package handshake
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/netem"
+	"tls_tests/src/pki"
+	"tls_tests/src/stats"
+)
+
+// TLS mode of the handshakes
+type Mode int
+
+const (
+	KEMTLS Mode = iota
+	PQTLS
+	Classic
+)
+
+func (m Mode) String() string {
+	switch m {
+	case PQTLS:
+		return "PQTLS"
+	case Classic:
+		return "TLS"
+	}
+	return "KEMTLS"
+}
+
+// Parses the mode names used in the experiment specs and the control protocol: kemtls, pqtls or tls (classic)
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "kemtls":
+		return KEMTLS, nil
+	case "pqtls":
+		return PQTLS, nil
+	case "tls":
+		return Classic, nil
+	}
+	return 0, fmt.Errorf("unknown mode %q", name)
+}
+
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(m.String())), nil
+}
+
+func (m *Mode) UnmarshalText(text []byte) error {
+	mode, err := ParseMode(string(text))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// Options of the clients and servers
+type Options struct {
+	Mode Mode
+
+	// KEMTLS PDK or TLS(cached) server cert.
+	CachedCert bool
+
+	// KEMTLS authentication with Classic McEliece
+	ClassicMcEliece bool
+
+	ClientAuth bool
+
+	// IP of the server and IP of the client auth certificate
+	ServerIP string
+	ClientIP string
+
+	Root pki.RootCA
+
+	// Intermediate CAs of the certificate chains
+	Chain pki.ChainShape
+
+	// Chains shared by the configurations of a single process. If nil, each configuration constructs its own
+	// chain from Root
+	Chains *pki.Chains `json:"-"`
+
+	// Allow the combinations of algorithms in different security levels
+	CrossLevel bool
+
+	// Follow each full client handshake by a handshake resuming its session
+	Resumption bool
+
+	// Serve the combinations of algorithms at a single port, selected by the server name of the client (SNI), as
+	// given by ServerName
+	SNI bool
+
+	// Handle the connections of the servers concurrently, each one in its own goroutine
+	Concurrent bool
+
+	// Key exchange of the initial key share of the client. The servers of other key exchanges answer with a
+	// HelloRetryRequest. If empty, the key share is the one of the server
+	KeyShare string
+
+	// Measure the CPU time and the heap allocations of each client handshake. They are accounted for the whole
+	// process, so they are only measured when the client performs its handshakes one at a time. The servers
+	// measure them unless Concurrent is set.
+	Resources bool `json:"-"`
+
+	// Successful handshakes of each kind performed with each server before the measured ones, which are not
+	// saved
+	Warmup int
+
+	// Number of handshakes measured by the server before saving its results
+	Handshakes int
+
+	// Emulated link of the connections. The server applies the profile of the client in synchronized mode.
+	Link netem.Profile
+}
+
+// NIST levels of a pair of algorithms of the default registry, recorded in the results files
+func Levels(kexAlgo, authAlgo string) stats.Levels {
+	return stats.Levels{
+		KEX:  algorithms.SecurityLevel(kexAlgo),
+		Auth: algorithms.SecurityLevel(authAlgo),
+		CA:   algorithms.CALevel(kexAlgo, authAlgo),
+	}
+}
+
+// Results files of the handshakes in the options mode
+func (o *Options) ResultsFiles() stats.Files {
+	var files stats.Files
+	if o.Mode == KEMTLS {
+		files = stats.KEMTLSFiles(o.CachedCert, o.ClassicMcEliece)
+	} else {
+		files = stats.TLSFiles(o.Mode == Classic, o.CachedCert)
+	}
+	files.Handshake = stats.FullHandshake
+	files.KeyShare = o.KeyShare
+	files.Link = o.Link.String()
+	files.Chain = o.Chain.Describe(o.Root)
+	return files
+}
+
+// Initialize TLS configuration and certificate chain for client/server. The default Intermediate CAs of the chain
+// are in the level given by algorithms.CALevel. Returns a nil configuration if the algorithms are not in the same
+// security level, unless opts.CrossLevel is set.
+func NewConfig(kexAlgoName, authAlgoName string, isClient bool, opts *Options) (*tls.Config, error) {
+	kexSecLevel := algorithms.SecurityLevel(kexAlgoName)
+	authSecLevel := algorithms.SecurityLevel(authAlgoName)
+
+	// auth in the same level
+	if kexSecLevel != authSecLevel && !opts.CrossLevel {
+		return nil, nil
+	}
+
+	kexAlgo, err := algorithms.NameToCurveID(kexAlgoName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client sends the key share of its first preference, and a second ClientHello with the key share of the
+	// server after its HelloRetryRequest
+	curves := []tls.CurveID{kexAlgo}
+	if isClient && opts.KeyShare != "" {
+		keyShare, err := algorithms.NameToCurveID(opts.KeyShare)
+		if err != nil {
+			return nil, err
+		}
+		if keyShare != kexAlgo {
+			curves = []tls.CurveID{keyShare, kexAlgo}
+		}
+	}
+
+	caLevel := algorithms.CALevel(kexAlgoName, authAlgoName)
+
+	var chain *pki.Chain
+	if opts.Chains != nil {
+		chain = opts.Chains.Get(caLevel)
+	} else {
+		chain = pki.NewChain(opts.Root, opts.Mode == Classic, opts.Chain, caLevel)
+	}
+
+	var authAlgo interface{}
+	switch opts.Mode {
+	case Classic:
+		authAlgo, err = algorithms.NameToClassicSigAlgo(authAlgoName)
+	case PQTLS:
+		authAlgo, err = algorithms.NameToSigID(authAlgoName)
+	default:
+		authAlgo, err = algorithms.NameToCurveID(authAlgoName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config *tls.Config
+	if isClient {
+		config = InitClient(kexAlgo, authAlgo, chain, opts)
+		config.CurvePreferences = curves
+		if opts.SNI {
+			config.ServerName = ServerName(kexAlgoName, authAlgoName, opts.Mode)
+		}
+	} else {
+		serverOpts := opts
+		if opts.SNI {
+			// The server certificate holds the server name along with the IP
+			o := *opts
+			o.ServerIP += "," + ServerName(kexAlgoName, authAlgoName, opts.Mode)
+			serverOpts = &o
+		}
+		config = InitServer(kexAlgo, authAlgo, chain, serverOpts)
+	}
+
+	return config, nil
+}
+
+// Server name of a combination of algorithms in SNI mode, e.g. p256-hqc-128.kemtls.test in KEMTLS, where the
+// authentication algorithm is the key exchange one, or x25519.ecdsa-p256.tls.test otherwise
+func ServerName(kexAlgoName, authAlgoName string, mode Mode) string {
+	label := func(name string) string {
+		return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+	}
+
+	name := label(kexAlgoName)
+	if authAlgoName != kexAlgoName {
+		name += "." + label(authAlgoName)
+	}
+	return name + "." + strings.ToLower(mode.String()) + ".test"
+}
+
+// Hex encoded SHA-256 fingerprint of a DER certificate
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// Sets the TLS mode of cfg, returning the key usage of the peer certificate
+func setMode(cfg *tls.Config, mode Mode) x509.KeyUsage {
+	switch mode {
+	case Classic:
+		return x509.KeyUsageDigitalSignature
+	case PQTLS:
+		cfg.PQTLSEnabled = true
+		return x509.KeyUsageDigitalSignature
+	default:
+		cfg.KEMTLSEnabled = true
+		return x509.KeyUsageKeyAgreement
+	}
+}
+
+// Initialize Server's TLS configuration
+func InitServer(kexAlgo tls.CurveID, certAlgo interface{}, chain *pki.Chain, opts *Options) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:                 tls.VersionTLS10,
+		MaxVersion:                 tls.VersionTLS13,
+		InsecureSkipVerify:         false,
+		SupportDelegatedCredential: false,
+		CurvePreferences:           []tls.CurveID{kexAlgo},
+	}
+
+	serverKeyUsage := setMode(cfg, opts.Mode)
+
+	if opts.ClientAuth {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = x509.NewCertPool()
+		cfg.ClientCAs.AddCert(chain.Root)
+	}
+
+	serverExtKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	certBytes, certPriv, err := pki.CreateCertificate(certAlgo, chain.IssuerCert, chain.IssuerPriv, false, false, "server", serverKeyUsage, serverExtKeyUsage, opts.ServerIP)
+	if err != nil {
+		panic(err)
+	}
+
+	tlsCert := new(tls.Certificate)
+
+	tlsCert.Certificate = append(tlsCert.Certificate, certBytes)
+	tlsCert.PrivateKey = certPriv
+	tlsCert.Leaf, err = x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		panic(err)
+	}
+
+	tlsCert.Certificate = append(tlsCert.Certificate, chain.IntermediatesDER()...)
+
+	cfg.Certificates = make([]tls.Certificate, 1)
+	cfg.Certificates[0] = *tlsCert
+
+	return cfg
+}
+
+// Initializes Client's TLS configuration
+func InitClient(kexAlgo tls.CurveID, certAlgo interface{}, chain *pki.Chain, opts *Options) *tls.Config {
+	ccfg := &tls.Config{
+		MinVersion:                 tls.VersionTLS10,
+		MaxVersion:                 tls.VersionTLS13,
+		InsecureSkipVerify:         false,
+		SupportDelegatedCredential: false,
+		CurvePreferences:           []tls.CurveID{kexAlgo},
+	}
+
+	clientKeyUsage := setMode(ccfg, opts.Mode)
+
+	if opts.ClientAuth {
+
+		hybridCert := new(tls.Certificate)
+		var err error
+
+		clientExtKeyUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+		certBytes, certPriv, err := pki.CreateCertificate(certAlgo, chain.IssuerCert, chain.IssuerPriv, false, false, "client", clientKeyUsage, clientExtKeyUsage, opts.ClientIP)
+		if err != nil {
+			panic(err)
+		}
+
+		hybridCert.Certificate = append(hybridCert.Certificate, certBytes)
+		hybridCert.PrivateKey = certPriv
+
+		hybridCert.Leaf, err = x509.ParseCertificate(hybridCert.Certificate[0])
+		if err != nil {
+			panic(err)
+		}
+
+		hybridCert.Certificate = append(hybridCert.Certificate, chain.IntermediatesDER()...)
+		ccfg.Certificates = make([]tls.Certificate, 1)
+		ccfg.Certificates[0] = *hybridCert
+	}
+
+	ccfg.RootCAs = x509.NewCertPool()
+
+	ccfg.RootCAs.AddCert(chain.Root)
+
+	// The session tickets of the server are kept by the client to resume its sessions
+	if opts.Resumption {
+		ccfg.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+	}
+
+	return ccfg
+}
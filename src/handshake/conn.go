@@ -0,0 +1,142 @@
+package handshake
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+
+	"tls_tests/src/stats"
+)
+
+// Application data exchanged after each handshake
+const (
+	ClientMessage = "hello, server"
+	ServerMessage = "hello, client"
+)
+
+// Handshake timings reported by the TLS event handler
+type TimingInfo struct {
+	Server tls.CFEventTLS13ServerHandshakeTimingInfo
+	Client tls.CFEventTLS13ClientHandshakeTimingInfo
+}
+
+func (ti *TimingInfo) eventHandler(event tls.CFEvent) {
+	switch e := event.(type) {
+	case tls.CFEventTLS13ServerHandshakeTimingInfo:
+		ti.Server = e
+	case tls.CFEventTLS13ClientHandshakeTimingInfo:
+		ti.Client = e
+	}
+}
+
+// Result of a handshake performed by Dial
+type Result struct {
+	Timing  TimingInfo
+	State   tls.ConnectionState
+	Flights stats.HandshakeFlights
+
+	// CPU time and heap allocations of the handshake, if opts.Resources is set, unless another handshake of the
+	// process overlapped it
+	Resources *stats.Resources
+
+	// Whether the handshake was performed in the expected mode, with client authentication if enabled
+	Success bool
+}
+
+func NewLocalListener(port string) net.Listener {
+	ln, err := net.Listen("tcp", "0.0.0.0:"+port)
+	if err != nil {
+		ln, err = net.Listen("tcp6", "[::1]:0")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ln
+}
+
+// Reports whether a classic TLS handshake, without KEMTLS nor PQTLS, was completed
+func DidClassicTLS(cconnState tls.ConnectionState) bool {
+	return cconnState.HandshakeComplete && !cconnState.DidKEMTLS && !cconnState.DidPQTLS
+}
+
+// Reports whether the handshake was performed in the mode of the options
+func didMode(cconnState tls.ConnectionState, mode Mode) bool {
+	switch mode {
+	case PQTLS:
+		return cconnState.DidPQTLS
+	case Classic:
+		return DidClassicTLS(cconnState)
+	}
+	return cconnState.DidKEMTLS
+}
+
+// Performs a handshake with the server at ipserver:port over the link of the options, followed by the exchange
+// of the application messages
+func Dial(tlsConfig *tls.Config, opts *Options, ipserver string, port string) (Result, error) {
+	// The event handler records the timings of this handshake only, so the configuration shared by the concurrent
+	// clients is copied. As tls.Dial, the server name is the one dialed if not configured.
+	tlsConfig = tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = ipserver
+	}
+
+	var result Result
+	tlsConfig.CFEventHandler = result.Timing.eventHandler
+
+	buf := make([]byte, len(ServerMessage))
+
+	conn, err := opts.Link.Dial("tcp", net.JoinHostPort(ipserver, port))
+	if err != nil {
+		return result, err
+	}
+
+	recorder := newFlightRecorder(conn)
+
+	client := tls.Client(recorder, tlsConfig)
+	defer client.Close()
+
+	u := startUsage(opts.Resources)
+	err = client.Handshake()
+	result.Resources = u.stop()
+	if err != nil {
+		return result, err
+	}
+	recorder.handshakeDone()
+
+	// The handshake only succeeds once the server answered the client message, which times the first byte
+	if _, err := client.Write([]byte(ClientMessage)); err != nil {
+		return result, fmt.Errorf("writing the client message: %v", err)
+	}
+
+	n, err := client.Read(buf)
+	if err == nil && n != len(ServerMessage) {
+		err = fmt.Errorf("read %d bytes of the server message", n)
+	}
+	if err != nil {
+		return result, fmt.Errorf("reading the server message: %v", err)
+	}
+	recorder.responseRead()
+
+	result.State = client.ConnectionState()
+	result.Flights = recorder.flights()
+
+	// A resumed handshake is authenticated by the pre-shared key of its session, without certificates
+	if result.State.DidResume {
+		result.Success = true
+		return result, nil
+	}
+
+	if !didMode(result.State, opts.Mode) {
+		log.Printf("Client unsuccessful %s\n", opts.Mode)
+		return result, nil
+	}
+
+	if opts.ClientAuth && !result.State.DidClientAuthentication {
+		log.Printf("Client unsuccessful %s with mutual authentication\n", opts.Mode)
+		return result, nil
+	}
+
+	result.Success = true
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package handshake
+
+import (
+	"bytes"
+	"net"
+	"time"
+
+	"tls_tests/src/stats"
+)
+
+// Write or read of a connection, recorded by flightRecorder
+type ioEvent struct {
+	at      time.Time
+	sent    bool
+	bytes   int
+	appData bool
+}
+
+// Offset of the random of a ServerHello in its record: record header (5 bytes), handshake header (4 bytes) and
+// legacy version (2 bytes)
+const (
+	serverHelloRandomStart = 11
+	serverHelloRandomEnd   = serverHelloRandomStart + 32
+)
+
+// Random of a ServerHello that is a HelloRetryRequest. See RFC 8446, Section 4.1.3.
+var helloRetryRequestRandom = []byte{
+	0xCF, 0x21, 0xAD, 0x74, 0xE5, 0x9A, 0x61, 0x11,
+	0xBE, 0x1D, 0x8C, 0x02, 0x1E, 0x65, 0xB8, 0x91,
+	0xC2, 0xA2, 0x11, 0x16, 0x7A, 0xBB, 0x8C, 0x5E,
+	0x07, 0x9E, 0x09, 0xE2, 0xC8, 0xA8, 0x33, 0x9C,
+}
+
+// Reports whether the first bytes of the server are a HelloRetryRequest record
+func isHelloRetryRequest(serverHello []byte) bool {
+	const recordTypeHandshake, typeServerHello = 22, 2
+	if len(serverHello) < serverHelloRandomEnd || serverHello[0] != recordTypeHandshake || serverHello[5] != typeServerHello {
+		return false
+	}
+	return bytes.Equal(serverHello[serverHelloRandomStart:serverHelloRandomEnd], helloRetryRequestRandom)
+}
+
+// Connection recording the time, direction and size of its writes and reads, in order to group the handshake
+// messages in flights. The writes and reads after handshakeDone are application data.
+type flightRecorder struct {
+	net.Conn
+	start   time.Time
+	events  []ioEvent
+	appData bool
+
+	firstByte time.Time
+
+	// First bytes read from the server, holding the random of its ServerHello
+	serverHello []byte
+}
+
+func newFlightRecorder(c net.Conn) *flightRecorder {
+	return &flightRecorder{Conn: c, start: time.Now()}
+}
+
+func (r *flightRecorder) Write(b []byte) (int, error) {
+	n, err := r.Conn.Write(b)
+	if n > 0 {
+		r.events = append(r.events, ioEvent{at: time.Now(), sent: true, bytes: n, appData: r.appData})
+	}
+	return n, err
+}
+
+func (r *flightRecorder) Read(b []byte) (int, error) {
+	n, err := r.Conn.Read(b)
+	if n > 0 {
+		r.events = append(r.events, ioEvent{at: time.Now(), sent: false, bytes: n, appData: r.appData})
+		if missing := serverHelloRandomEnd - len(r.serverHello); missing > 0 {
+			if missing > n {
+				missing = n
+			}
+			r.serverHello = append(r.serverHello, b[:missing]...)
+		}
+	}
+	return n, err
+}
+
+func (r *flightRecorder) handshakeDone() {
+	r.appData = true
+}
+
+// Records the time the server response to the first application message was received
+func (r *flightRecorder) responseRead() {
+	r.firstByte = time.Now()
+}
+
+// Groups the handshake writes and reads in flights. The client sends its first application byte once the handshake
+// is done, so the round trips are the server flights of the handshake.
+func (r *flightRecorder) flights() stats.HandshakeFlights {
+	var f stats.HandshakeFlights
+
+	for _, e := range r.events {
+		if e.appData {
+			break
+		}
+
+		last := len(f.Flights) - 1
+		if last >= 0 && f.Flights[last].Client == e.sent {
+			f.Flights[last].Bytes += e.bytes
+			continue
+		}
+
+		f.Flights = append(f.Flights, stats.Flight{
+			Client: e.sent,
+			Bytes:  e.bytes,
+			Start:  float64(e.at.Sub(r.start)) / float64(time.Millisecond),
+		})
+		if !e.sent {
+			f.RoundTrips++
+		}
+	}
+
+	for _, flight := range f.Flights {
+		if !flight.Client {
+			f.ServerFirstFlight = flight.Bytes
+			break
+		}
+	}
+
+	f.HelloRetryRequest = isHelloRetryRequest(r.serverHello)
+
+	if !r.firstByte.IsZero() {
+		f.TimeToFirstByte = float64(r.firstByte.Sub(r.start)) / float64(time.Millisecond)
+	}
+
+	return f
+}
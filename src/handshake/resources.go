@@ -0,0 +1,93 @@
+package handshake
+
+import (
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"tls_tests/src/stats"
+)
+
+// CPU time and heap allocations of the process, read before and after a handshake to measure its resources. They
+// are accounted for the whole process, so they only measure a handshake performed while no other one is.
+type usage struct {
+	user, system        time.Duration
+	mallocs, totalAlloc uint64
+
+	// Whether the resources of the handshake are measured, and whether another handshake of the process was in
+	// progress meanwhile
+	measured   bool
+	overlapped bool
+}
+
+// Handshakes in progress in the process, measured or not, such as the client and the server sides of a self test
+// or the connections of several servers
+var (
+	inProgressMu sync.Mutex
+	inProgress   = make(map[*usage]bool)
+)
+
+// Tracks a handshake of the process until stop, reading the usage of the process before it if measure is set.
+// The memory statistics, which stop the world, are read first so that their CPU time is not measured.
+func startUsage(measure bool) *usage {
+	u := &usage{measured: measure}
+
+	inProgressMu.Lock()
+	for other := range inProgress {
+		other.overlapped = true
+		u.overlapped = true
+	}
+	inProgress[u] = true
+	inProgressMu.Unlock()
+
+	if !measure {
+		return u
+	}
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	u.mallocs, u.totalAlloc = ms.Mallocs, ms.TotalAlloc
+
+	u.user, u.system = cpuTimes()
+	return u
+}
+
+// Resources used since the usage was read by startUsage, or nil if they are not measured. The resources of a
+// handshake that overlapped another one of the process are not its own, so they are only marked as overlapped.
+func (u *usage) stop() *stats.Resources {
+	var user, system time.Duration
+	var ms runtime.MemStats
+	if u.measured {
+		user, system = cpuTimes()
+		runtime.ReadMemStats(&ms)
+	}
+
+	inProgressMu.Lock()
+	delete(inProgress, u)
+	overlapped := u.overlapped
+	inProgressMu.Unlock()
+
+	switch {
+	case !u.measured:
+		return nil
+	case overlapped:
+		return &stats.Resources{Overlapped: true}
+	}
+
+	return &stats.Resources{
+		UserCPU:    float64(user-u.user) / float64(time.Millisecond),
+		SystemCPU:  float64(system-u.system) / float64(time.Millisecond),
+		Allocs:     ms.Mallocs - u.mallocs,
+		AllocBytes: ms.TotalAlloc - u.totalAlloc,
+	}
+}
+
+// User and system CPU time of the process
+func cpuTimes() (user, system time.Duration) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		panic(err)
+	}
+	return time.Duration(ru.Utime.Nano()), time.Duration(ru.Stime.Nano())
+}
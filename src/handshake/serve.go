@@ -0,0 +1,559 @@
+package handshake
+
+import (
+	"context"
+	"crypto/kem"
+	"crypto/liboqs_sig"
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/stats"
+)
+
+// Cause of a failed server handshake
+type FailureCause int
+
+const (
+	FailureAccept FailureCause = iota
+	FailureHandshake
+	FailureAppData // Exchange of the application messages after the handshake
+	FailureMode    // Handshake not performed in the mode of the options
+	FailureClientAuth
+)
+
+func (c FailureCause) String() string {
+	switch c {
+	case FailureAccept:
+		return "accept"
+	case FailureHandshake:
+		return "handshake"
+	case FailureAppData:
+		return "appdata"
+	case FailureMode:
+		return "mode"
+	case FailureClientAuth:
+		return "clientauth"
+	}
+	return fmt.Sprintf("FailureCause(%d)", int(c))
+}
+
+func (c FailureCause) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *FailureCause) UnmarshalText(text []byte) error {
+	for cause := FailureAccept; cause <= FailureClientAuth; cause++ {
+		if cause.String() == string(text) {
+			*c = cause
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown failure cause %q", text)
+}
+
+// Summary of a server loop
+type ServeSummary struct {
+	Successes int                  `json:"successes"`
+	Failures  map[FailureCause]int `json:"failures,omitempty"`
+
+	// Result lines saved, the last ones partially if the loop stopped before opts.Handshakes handshakes
+	Saved   int  `json:"saved"`
+	Partial bool `json:"partial,omitempty"`
+
+	// Reason the loop stopped: target, canceled, discarded, deadline or the listener error
+	Stopped string `json:"stopped"`
+
+	// Successful handshakes per second, from the start of the first measured connection to the end of the last
+	// successful one
+	Rate float64 `json:"rate,omitempty"`
+}
+
+func (s ServeSummary) String() string {
+	var failures []string
+	for cause, n := range s.Failures {
+		failures = append(failures, fmt.Sprintf("%s: %d", cause, n))
+	}
+	sort.Strings(failures)
+
+	summary := fmt.Sprintf("%d successes, %d saved", s.Successes, s.Saved)
+	if s.Partial {
+		summary += " (partial)"
+	}
+	if s.Rate > 0 {
+		summary += fmt.Sprintf(", %.1f handshakes/s", s.Rate)
+	}
+	if len(failures) > 0 {
+		summary += ", failures " + strings.Join(failures, ", ")
+	}
+	return summary + ", stopped: " + s.Stopped
+}
+
+// Server timings of the handshakes not saved yet
+type serverTimings struct {
+	fullProtocol      []float64
+	writeServerHello  []float64
+	writeCertVerify   []float64
+	readKEMCiphertext []float64
+
+	// Resources of the handshakes, if the connections are handled one at a time
+	resources []stats.Resources
+
+	// Successful handshakes still to be discarded as warmup
+	warmup int
+
+	lastState tls.ConnectionState
+}
+
+func (t *serverTimings) add(timingState TimingInfo, state tls.ConnectionState, mode Mode, resources *stats.Resources) {
+	t.fullProtocol = append(t.fullProtocol, float64(timingState.Server.FullProtocol)/float64(time.Millisecond))
+	t.writeServerHello = append(t.writeServerHello, float64(timingState.Server.WriteServerHello)/float64(time.Millisecond))
+
+	if mode == KEMTLS {
+		t.readKEMCiphertext = append(t.readKEMCiphertext, float64(timingState.Server.ReadKEMCiphertext)/float64(time.Millisecond))
+	} else {
+		t.writeCertVerify = append(t.writeCertVerify, float64(timingState.Server.WriteCertificateVerify)/float64(time.Millisecond))
+	}
+	if resources != nil {
+		t.resources = append(t.resources, *resources)
+	}
+	t.lastState = state
+}
+
+// Accepts connections at port until the process ends, measuring the server side of the handshakes as
+// ServeListener
+func Serve(tlsConfig *tls.Config, opts *Options, port string) ServeSummary {
+	return ServeListener(context.Background(), NewLocalListener(port), tlsConfig, opts, 0)
+}
+
+// Accepts the connections of ln, measuring the server side of the handshakes, concurrently if opts.Concurrent
+// is set. The results are saved each opts.Handshakes successful handshakes of a kind, as the full and the
+// resumed ones are saved separately. In cached certificate mode, the first connection, which retrieves the
+// server certificate, is not measured, nor are the first opts.Warmup successful handshakes of each kind.
+//
+// The loop stops after target successful handshakes, if target is positive, or when ctx is done, and the
+// connections in progress are finished first. The measurements not saved yet are then saved as partial results.
+// ln is closed when the loop stops.
+func ServeListener(ctx context.Context, ln net.Listener, tlsConfig *tls.Config, opts *Options, target int) ServeSummary {
+	mux := NewMux(ln)
+	mux.Concurrent = opts.Concurrent
+	summary := mux.Handle("", tlsConfig, opts, target)
+	mux.Serve(ctx)
+	return <-summary
+}
+
+// Handshake servers sharing a listener, each one measuring the handshakes of its own configuration as
+// ServeListener. The configuration of a connection is selected by the server name (SNI) of its ClientHello,
+// unless a single server without a name is handled, which accepts every connection.
+//
+// The connections are handled one at a time or, if Concurrent is set, each one in its own goroutine. Each
+// connection is handshaked with a clone of the configuration of its server, whose event handler reports the
+// timings of that connection only. The CPU time and the heap allocations of the handshakes, which are accounted
+// for the whole process, are only measured when the connections are handled one at a time.
+type Mux struct {
+	ln net.Listener
+
+	// Handle each connection in its own goroutine. Set before Serve.
+	Concurrent bool
+
+	mu      sync.Mutex
+	servers map[string]*muxServer
+	closed  bool
+
+	// Connections in progress, which are finished before a server is stopped
+	active int
+	idle   *sync.Cond
+}
+
+// Server of a Mux, and its measurements not saved yet
+type muxServer struct {
+	name      string
+	tlsConfig *tls.Config
+	opts      *Options
+	files     stats.Files
+	target    int
+
+	// The full and the resumed handshakes are saved separately
+	full, resumed serverTimings
+
+	ignoreFirstConn bool
+
+	// Start of the first measured connection and end of the last successful one
+	first, last time.Time
+
+	summary ServeSummary
+	done    chan ServeSummary
+}
+
+// Connection handled by a Mux, with the timings reported by its configuration
+type muxConn struct {
+	net.Conn
+	start       time.Time
+	timingState TimingInfo
+	resources   *stats.Resources
+	server      *muxServer
+}
+
+func NewMux(ln net.Listener) *Mux {
+	m := &Mux{ln: ln, servers: make(map[string]*muxServer)}
+	m.idle = sync.NewCond(&m.mu)
+	return m
+}
+
+// Adds the server of tlsConfig, selected by the clients with serverName, which stops after target successful
+// handshakes, if target is positive. Its summary is sent to the returned channel once it stops.
+func (m *Mux) Handle(serverName string, tlsConfig *tls.Config, opts *Options, target int) <-chan ServeSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := &muxServer{
+		name:            serverName,
+		tlsConfig:       tlsConfig,
+		opts:            opts,
+		files:           opts.ResultsFiles(),
+		target:          target,
+		ignoreFirstConn: opts.CachedCert,
+		summary:         ServeSummary{Failures: make(map[FailureCause]int)},
+		done:            make(chan ServeSummary, 1),
+	}
+	s.full.warmup, s.resumed.warmup = opts.Warmup, opts.Warmup
+
+	if m.closed {
+		s.summary.Stopped = "closed"
+		s.done <- s.summary
+		return s.done
+	}
+
+	// The connections are handshaked with clones of the configuration, which share its session ticket key to
+	// resume the sessions of each other
+	if tlsConfig.SessionTicketKey == [32]byte{} {
+		if _, err := rand.Read(tlsConfig.SessionTicketKey[:]); err != nil {
+			panic(err)
+		}
+	}
+
+	m.servers[serverName] = s
+	return s.done
+}
+
+// Stops the server of serverName once the connections in progress are finished, saving its partial results.
+// The listener is closed once all the servers are stopped.
+func (m *Mux) Stop(serverName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.waitIdle()
+	if s, ok := m.servers[serverName]; ok {
+		m.finish(s, "canceled")
+	}
+}
+
+// Stops the server of serverName as Stop, discarding its results not saved yet instead, such as the ones of a
+// server given up by the client
+func (m *Mux) Discard(serverName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.waitIdle()
+	if s, ok := m.servers[serverName]; ok {
+		s.full, s.resumed = serverTimings{}, serverTimings{}
+		m.finish(s, "discarded")
+	}
+}
+
+// Waits until no connection is in progress. Called with m.mu held.
+func (m *Mux) waitIdle() {
+	for m.active > 0 {
+		m.idle.Wait()
+	}
+}
+
+// Saves the partial results of a server and sends its summary, removing it from the mux
+func (m *Mux) finish(s *muxServer, stopped string) {
+	if len(s.full.fullProtocol) > 0 {
+		s.summary.Partial = true
+		s.save(&s.full, stats.FullHandshake)
+	}
+	if len(s.resumed.fullProtocol) > 0 {
+		s.summary.Partial = true
+		s.save(&s.resumed, stats.ResumedHandshake)
+	}
+
+	if elapsed := s.last.Sub(s.first); elapsed > 0 {
+		s.summary.Rate = float64(s.summary.Successes) / elapsed.Seconds()
+	}
+
+	s.summary.Stopped = stopped
+	s.done <- s.summary
+	delete(m.servers, s.name)
+
+	if len(m.servers) == 0 && !m.closed {
+		m.closed = true
+		m.ln.Close()
+	}
+}
+
+// Accepts the connections of the listener until all the servers are stopped or ctx is done, when the servers
+// still measuring are stopped once the connections in progress are finished. The listener is closed when the
+// loop stops.
+func (m *Mux) Serve(ctx context.Context) {
+	// Closing the listener interrupts the pending Accept once ctx is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.ln.Close()
+		case <-stop:
+		}
+	}()
+	defer m.ln.Close()
+
+	for {
+		serverConn, err := m.ln.Accept()
+		m.mu.Lock()
+
+		if err != nil {
+			stopped := err.Error()
+			if ctx.Err() == context.DeadlineExceeded {
+				stopped = "deadline"
+			} else if ctx.Err() != nil {
+				stopped = "canceled"
+			} else if ne, ok := err.(net.Error); ok && ne.Temporary() && len(m.servers) > 0 {
+				for _, s := range m.servers {
+					s.fail(FailureAccept, err)
+				}
+				m.mu.Unlock()
+				continue
+			}
+
+			m.waitIdle()
+			for _, s := range m.servers {
+				m.finish(s, stopped)
+			}
+			m.closed = true
+			m.mu.Unlock()
+			return
+		}
+
+		m.active++
+		m.mu.Unlock()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			serverConn.SetDeadline(deadline)
+		}
+
+		c := &muxConn{Conn: serverConn, start: time.Now()}
+		if m.Concurrent {
+			go m.handle(c)
+		} else {
+			m.handle(c)
+		}
+	}
+}
+
+// Selects the server of a connection, returning a clone of its configuration reporting the timings of the
+// connection. Called with m.mu held.
+func (m *Mux) config(c *muxConn, serverName string) (*tls.Config, error) {
+	s, ok := m.servers[serverName]
+	if !ok {
+		return nil, fmt.Errorf("no server for the server name %q", serverName)
+	}
+	c.server = s
+
+	cfg := s.tlsConfig.Clone()
+	cfg.CFEventHandler = c.timingState.eventHandler
+	return cfg, nil
+}
+
+// Configuration of the handshake of a connection, which is the one of its server once it is known
+func (m *Mux) connConfig(c *muxConn) *tls.Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.servers[""]; ok && len(m.servers) == 1 {
+		cfg, _ := m.config(c, "")
+		return cfg
+	}
+
+	return &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			return m.config(c, hello.ServerName)
+		},
+	}
+}
+
+// Performs the handshake of a connection and the exchange of the application messages, measuring it in the
+// server selected by the client
+func (m *Mux) handle(c *muxConn) {
+	server := tls.Server(c.Conn, m.connConfig(c))
+
+	u := startUsage(!m.Concurrent)
+	err := server.Handshake()
+	c.resources = u.stop()
+	if err != nil {
+		c.Close()
+		m.account(c, tls.ConnectionState{}, FailureHandshake, err)
+		return
+	}
+
+	buf := make([]byte, len(ClientMessage))
+
+	//server read client hello
+	n, err := server.Read(buf)
+	if err == nil && n != len(ClientMessage) {
+		err = fmt.Errorf("read %d bytes of the client message", n)
+	}
+
+	//server responds
+	if err == nil {
+		_, err = server.Write([]byte(ServerMessage))
+	}
+
+	cconnState := server.ConnectionState()
+	c.Close()
+
+	m.account(c, cconnState, FailureAppData, err)
+}
+
+// Measures a finished connection in its server, unless the server is stopped. If err is not nil, the connection
+// failed with cause.
+func (m *Mux) account(c *muxConn, cconnState tls.ConnectionState, cause FailureCause, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	defer func() {
+		m.active--
+		if m.active == 0 {
+			m.idle.Broadcast()
+		}
+	}()
+
+	s := c.server
+	if s == nil {
+		log.Printf("Server %s failure: %v\n", cause, err)
+		return
+	}
+	if m.servers[s.name] != s {
+		return
+	}
+
+	if err != nil {
+		s.fail(cause, err)
+		return
+	}
+
+	if s.ignoreFirstConn {
+		s.ignoreFirstConn = false
+		return
+	}
+
+	// A resumed handshake is authenticated by the pre-shared key of its session, without certificates
+	t, kind := &s.full, stats.FullHandshake
+	if cconnState.DidResume {
+		t, kind = &s.resumed, stats.ResumedHandshake
+	} else {
+		if !didMode(cconnState, s.opts.Mode) {
+			s.fail(FailureMode, fmt.Errorf("server unsuccessful %s", s.opts.Mode))
+			return
+		}
+
+		if s.opts.ClientAuth && !cconnState.DidClientAuthentication {
+			s.fail(FailureClientAuth, fmt.Errorf("server unsuccessful %s with mutual authentication", s.opts.Mode))
+			return
+		}
+	}
+
+	if t.warmup > 0 {
+		t.warmup--
+		return
+	}
+
+	if s.first.IsZero() || c.start.Before(s.first) {
+		s.first = c.start
+	}
+	s.last = time.Now()
+
+	s.summary.Successes++
+	t.add(c.timingState, cconnState, s.opts.Mode, c.resources)
+
+	if len(t.fullProtocol) == s.opts.Handshakes {
+		s.save(t, kind)
+	}
+
+	if s.target > 0 && s.summary.Successes >= s.target {
+		m.finish(s, "target")
+	}
+}
+
+func (s *muxServer) fail(cause FailureCause, err error) {
+	s.summary.Failures[cause]++
+	log.Printf("Server %s failure: %v\n", cause, err)
+}
+
+// Saves the timings of t, of the handshakes of kind
+func (s *muxServer) save(t *serverTimings, kind string) {
+	count := len(t.fullProtocol)
+	lastState := t.lastState
+	tlsConfig := s.tlsConfig
+
+	kKEX, err := algorithms.CurveIDToName(tlsConfig.CurvePreferences[0])
+	if err != nil {
+		log.Printf("Server results not saved: %v\n", err)
+		return
+	}
+
+	files := s.files
+	files.Handshake = kind
+
+	handshakeSizes := make(map[string]uint32)
+	handshakeSizes["ServerHello"] = lastState.ServerHandshakeSizes.ServerHello
+	handshakeSizes["EncryptedExtensions"] = lastState.ServerHandshakeSizes.EncryptedExtensions
+	handshakeSizes["Certificate"] = lastState.ServerHandshakeSizes.Certificate
+	handshakeSizes["CertificateRequest"] = lastState.ServerHandshakeSizes.CertificateRequest
+	handshakeSizes["Finished"] = lastState.ServerHandshakeSizes.Finished
+
+	if s.opts.Mode == KEMTLS {
+		priv, ok := tlsConfig.Certificates[0].PrivateKey.(*kem.PrivateKey)
+		if !ok {
+			panic("TLS certificate does not contain a KEM private key")
+		}
+		kAuth, err := kem.GetLiboqsKEMName(priv.KEMId)
+		if err != nil {
+			panic(err)
+		}
+
+		handshakeSizes["ServerKEMCiphertext"] = lastState.ServerHandshakeSizes.ServerKEMCiphertext
+
+		stats.KEMTLSSaveCSVServer(files, t.fullProtocol, t.writeServerHello, t.readKEMCiphertext, kKEX, kAuth, Levels(kKEX, kAuth), count, handshakeSizes, t.resources)
+	} else {
+		var kAuth string
+
+		if s.opts.Mode == Classic {
+			kAuth, err = algorithms.ClassicSigToName(tlsConfig.Certificates[0].PrivateKey)
+		} else {
+			priv, _ := tlsConfig.Certificates[0].PrivateKey.(*liboqs_sig.PrivateKey)
+			kAuth, err = algorithms.SigIDToName(priv.SigId)
+		}
+		if err != nil {
+			log.Printf("Server results not saved: %v\n", err)
+			return
+		}
+
+		handshakeSizes["CertificateVerify"] = lastState.ServerHandshakeSizes.CertificateVerify
+
+		stats.TLSSaveCSVServer(files, t.fullProtocol, t.writeServerHello, t.writeCertVerify, kKEX, kAuth, Levels(kKEX, kAuth), count, handshakeSizes, t.resources)
+	}
+
+	s.summary.Saved += count
+	*t = serverTimings{}
+}
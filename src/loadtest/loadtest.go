@@ -0,0 +1,329 @@
+// Package loadtest performs HTTP load tests against the TLS servers, and launches them.
+package loadtest
+
+import (
+	"crypto/tls"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	netURL "net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"tls_tests/src/handshake"
+	"tls_tests/src/netem"
+)
+
+type Configuration struct {
+	URLs       []string
+	Method     string
+	PostData   []byte
+	Requests   int64
+	Period     int64
+	KeepAlive  bool
+	AuthHeader string
+
+	MyClient fasthttp.Client
+
+	// If set, clients stop issuing requests once it is reached
+	Deadline time.Time
+}
+
+type Result struct {
+	Requests      int64
+	Success       int64
+	NetworkFailed int64
+	BadFailed     int64
+}
+
+var readThroughput int64
+var writeThroughput int64
+
+type MyConn struct {
+	net.Conn
+}
+
+func (this *MyConn) Read(b []byte) (n int, err error) {
+	len, err := this.Conn.Read(b)
+
+	if err == nil {
+		atomic.AddInt64(&readThroughput, int64(len))
+	}
+
+	return len, err
+}
+
+func (this *MyConn) Write(b []byte) (n int, err error) {
+	len, err := this.Conn.Write(b)
+
+	if err == nil {
+		atomic.AddInt64(&writeThroughput, int64(len))
+	}
+
+	return len, err
+}
+
+// Returns the read and write throughput counted by the connections of MyDialer
+func Throughput() (read, write int64) {
+	return atomic.LoadInt64(&readThroughput), atomic.LoadInt64(&writeThroughput)
+}
+
+// Results file of the load tests in the options mode
+func ResultsFileName(opts *handshake.Options) string {
+	switch opts.Mode {
+	case handshake.Classic:
+		if opts.CachedCert {
+			return "csv/load_test_tls_cached_cert.csv"
+		} else {
+			return "csv/load_test_tls.csv"
+		}
+	case handshake.PQTLS:
+		if opts.CachedCert {
+			return "csv/load_test_pqtls_cached_cert.csv"
+		} else {
+			return "csv/load_test_pqtls.csv"
+		}
+	default:
+		if opts.CachedCert {
+			return "csv/load_test_kemtls_pdk.csv"
+		} else {
+			return "csv/load_test_kemtls.csv"
+		}
+	}
+}
+
+func SaveCSV(opts *handshake.Options, kemName, authName string, clients int, results map[int]*Result, elapsed, readThroughput, writeThroughput int64) {
+	var requests int64
+	var success int64
+	var networkFailed int64
+	var badFailed int64
+
+	for _, result := range results {
+		requests += result.Requests
+		success += result.Success
+		networkFailed += result.NetworkFailed
+		badFailed += result.BadFailed
+	}
+
+	fileName := ResultsFileName(opts)
+
+	if _, err := os.Stat(fileName); errors.Is(err, os.ErrNotExist) {
+		csvFile, err := os.Create(fileName)
+		if err != nil {
+			panic(err)
+		}
+		csvwriter := csv.NewWriter(csvFile)
+
+		header := []string{"KEX", "Auth", "Number of clients", "Requests", "Successful requests", "Network failed", "Bad requests failed (!2xx)", "Successful requests rate (hits/sec)", "Read throughput (bytes/sec)", "Write throughput (bytes/sec)", "Test time (sec)", "Link"}
+		if err := csvwriter.Write(header); err != nil {
+			panic(err)
+		}
+		csvwriter.Flush()
+		csvFile.Close()
+	}
+
+	csvFile, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		panic(err)
+	}
+
+	csvwriter := csv.NewWriter(csvFile)
+
+	arrayStr := []string{
+		kemName, authName,
+		fmt.Sprintf("%d", clients),
+		fmt.Sprintf("%d", requests),
+		fmt.Sprintf("%d", success),
+		fmt.Sprintf("%d", networkFailed),
+		fmt.Sprintf("%d", badFailed),
+		fmt.Sprintf("%d", success/elapsed),
+		fmt.Sprintf("%d", readThroughput/elapsed),
+		fmt.Sprintf("%d", writeThroughput/elapsed),
+		fmt.Sprintf("%d", elapsed),
+		opts.Link.String(),
+	}
+
+	if err := csvwriter.Write(arrayStr); err != nil {
+		panic(err)
+	}
+
+	csvwriter.Flush()
+	csvFile.Close()
+
+	fmt.Println()
+	fmt.Printf("Requests:                       %10d hits\n", requests)
+	fmt.Printf("Successful requests:            %10d hits\n", success)
+	fmt.Printf("Network failed:                 %10d hits\n", networkFailed)
+	fmt.Printf("Bad requests failed (!2xx):     %10d hits\n", badFailed)
+	fmt.Printf("Successful requests rate:       %10d hits/sec\n", success/elapsed)
+	fmt.Printf("Read throughput:                %10d bytes/sec\n", readThroughput/elapsed)
+	fmt.Printf("Write throughput:               %10d bytes/sec\n", writeThroughput/elapsed)
+	fmt.Printf("Test time:                      %10d sec\n", elapsed)
+	fmt.Printf("Link:                           %10s\n", opts.Link)
+}
+
+// Sets the client TLS configuration for the load test. In cached certificate mode, the server certificate is
+// retrieved from the temporary server listening at the port following the one in url.
+func InitTLSConfig(configuration *Configuration, opts *handshake.Options, kexName, authName, url string) {
+	var err error
+
+	configuration.MyClient.TLSConfig, err = handshake.NewConfig(kexName, authName, true, opts)
+	if err != nil {
+		log.Fatalf("Error in initClientAndAuth: %v", err)
+	}
+	if configuration.MyClient.TLSConfig == nil {
+		log.Fatal("Error in initClientAndAuth: result config is nil")
+	}
+
+	if opts.CachedCert {
+
+		u, err := netURL.Parse(url)
+		if err != nil {
+			panic(err)
+		}
+
+		host, port, _ := net.SplitHostPort(u.Host)
+
+		portInt, err := strconv.Atoi(port)
+		if err != nil {
+			panic(err)
+		}
+
+		portInt = portInt + 1
+		port = strconv.Itoa(portInt)
+
+		client, err := tls.Dial("tcp", host+":"+port, configuration.MyClient.TLSConfig)
+		if err != nil {
+			fmt.Print(err)
+		}
+		defer client.Close()
+
+		cconnState := client.ConnectionState()
+
+		if err != nil {
+			fmt.Println("Error establishing first connection for cached certificate mode")
+			log.Fatal(err)
+		} else {
+			fmt.Println("Success establishing first connection for cached certificate mode")
+		}
+
+		configuration.MyClient.TLSConfig.CachedCert = cconnState.CertificateMessage
+	}
+}
+
+// Dials the connections of the load test clients over the emulated link, counting their throughput
+func MyDialer(link netem.Profile) func(address string) (conn net.Conn, err error) {
+	return func(address string) (net.Conn, error) {
+		conn, err := link.Dial("tcp", address)
+		if err != nil {
+			return nil, err
+		}
+
+		myConn := &MyConn{Conn: conn}
+
+		return myConn, nil
+	}
+}
+
+func Client(configuration *Configuration, result *Result, done *sync.WaitGroup) {
+	for result.Requests < configuration.Requests && !configuration.expired() {
+		for _, tmpUrl := range configuration.URLs {
+
+			req := fasthttp.AcquireRequest()
+
+			req.SetRequestURI(tmpUrl)
+			req.Header.SetMethodBytes([]byte(configuration.Method))
+
+			if configuration.KeepAlive == true {
+				req.Header.Set("Connection", "keep-alive")
+			} else {
+				req.Header.Set("Connection", "close")
+			}
+
+			if len(configuration.AuthHeader) > 0 {
+				req.Header.Set("Authorization", configuration.AuthHeader)
+			}
+
+			req.SetBody(configuration.PostData)
+
+			resp := fasthttp.AcquireResponse()
+			err := configuration.MyClient.Do(req, resp)
+			statusCode := resp.StatusCode()
+			result.Requests++
+			fasthttp.ReleaseRequest(req)
+			fasthttp.ReleaseResponse(resp)
+
+			if err != nil {
+				result.NetworkFailed++
+				continue
+			}
+
+			if statusCode == fasthttp.StatusOK {
+				result.Success++
+			} else {
+				result.BadFailed++
+			}
+		}
+	}
+
+	done.Done()
+}
+
+// Performs a load test of period seconds with the given number of concurrent clients against url, saving the
+// results in the load test CSV
+func Run(opts *handshake.Options, kexName, authName, url string, clients int, period int64, keepAlive bool) {
+	configuration := &Configuration{
+		URLs:      []string{url},
+		Method:    "GET",
+		KeepAlive: keepAlive,
+		Requests:  int64((1 << 63) - 1),
+		Period:    period,
+	}
+
+	configuration.MyClient.ReadTimeout = 5000 * time.Millisecond
+	configuration.MyClient.WriteTimeout = 5000 * time.Millisecond
+	configuration.MyClient.MaxConnsPerHost = clients
+	configuration.MyClient.Dial = MyDialer(opts.Link)
+
+	InitTLSConfig(configuration, opts, kexName, authName, url)
+
+	atomic.StoreInt64(&readThroughput, 0)
+	atomic.StoreInt64(&writeThroughput, 0)
+
+	var done sync.WaitGroup
+	results := make(map[int]*Result)
+
+	fmt.Printf("Dispatching %d clients\n", clients)
+
+	startTime := time.Now()
+	configuration.Deadline = startTime.Add(time.Duration(period) * time.Second)
+
+	done.Add(clients)
+	for i := 0; i < clients; i++ {
+		result := &Result{}
+		results[i] = result
+		go Client(configuration, result, &done)
+	}
+	fmt.Println("Waiting for results...")
+	done.Wait()
+
+	elapsed := int64(time.Since(startTime).Seconds())
+	if elapsed == 0 {
+		elapsed = 1
+	}
+
+	read, write := Throughput()
+	SaveCSV(opts, kexName, authName, clients, results, elapsed, read, write)
+}
+
+// Reports whether the configured deadline, if any, has passed
+func (c *Configuration) expired() bool {
+	return !c.Deadline.IsZero() && time.Now().After(c.Deadline)
+}
@@ -0,0 +1,56 @@
+package loadtest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"tls_tests/src/handshake"
+	"tls_tests/src/netem"
+)
+
+// Launches an HTTPS server that serves the static/ directory at port, over the emulated link
+func LaunchHTTPSServer(serverConfig *tls.Config, port string, link netem.Profile) error {
+	// Each server has its own mux, so that a process may launch several of them
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir("./static")))
+
+	addr := ":" + port
+
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   mux,
+		TLSConfig: serverConfig,
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go server.ServeTLS(link.Listener(ln), "", "")
+	return nil
+}
+
+// Launches a temporary TLS server to be used by the load test client in order to obtain the server certificate
+// preliminarily to the HTTP load test
+func LaunchTempServer(tlsConfig *tls.Config, port string) {
+
+	ln := handshake.NewLocalListener(port)
+	defer ln.Close()
+
+	serverConn, err := ln.Accept()
+	if err != nil {
+		fmt.Println(err)
+	}
+	server := tls.Server(serverConn, tlsConfig)
+	if err := server.Handshake(); err != nil {
+		fmt.Printf("Handshake error %v\n", err)
+	}
+
+	err = server.Close()
+	if err != nil {
+		fmt.Println(err)
+	}
+}
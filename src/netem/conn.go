@@ -0,0 +1,223 @@
+package netem
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Bytes a writer may queue ahead of the link, as the send buffer of a socket
+const sendBuffer = 64 * 1024
+
+// Time Close waits for the queued segments to be delivered, after the delivery time of the last one, before
+// giving up on a peer that does not read them
+const closeTimeout = 5 * time.Second
+
+// Segment written to an emulated connection, and the time it is delivered to the underlying one
+type segment struct {
+	data    []byte
+	deliver time.Time
+}
+
+// Connection over an emulated link. The writes are split in MSS segments, which are serialized at the link
+// bandwidth, delayed, and delayed again by the retransmission delay when they are lost. The segments are
+// delivered in order, as in TCP, by a goroutine writing to the underlying connection. Write blocks while more
+// than sendBuffer bytes wait to be serialized, so a throttled link pushes back on its writer, and only the
+// segments in flight and the send buffer are queued.
+type Conn struct {
+	net.Conn
+	profile Profile
+	rand    *rand.Rand
+
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	queue []segment
+
+	// Time the link finishes serializing the queued segments, and delivery time of the last one
+	linkFree     time.Time
+	lastDelivery time.Time
+
+	// Error of the underlying connection, returned by the following writes
+	err    error
+	closed bool
+
+	// Closed once the queued segments are delivered after Close
+	done chan struct{}
+}
+
+// Wraps c in a connection over the link of the profile. c is returned as it is if the profile does not emulate
+// a link.
+func (p Profile) Conn(c net.Conn) net.Conn {
+	if !p.Enabled() {
+		return c
+	}
+
+	ec := &Conn{
+		Conn:    c,
+		profile: p,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		done:    make(chan struct{}),
+	}
+	ec.cond = sync.NewCond(&ec.mu)
+
+	go ec.deliver()
+	return ec
+}
+
+// Connects to address over the link of the profile
+func (p Profile) Dial(network, address string) (net.Conn, error) {
+	c, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return p.Conn(c), nil
+}
+
+// One way delay of a segment
+func (p Profile) delay(r *rand.Rand) time.Duration {
+	if p.Jitter == 0 {
+		return p.Delay
+	}
+	return p.Delay - p.Jitter + time.Duration(r.Int63n(int64(2*p.Jitter)+1))
+}
+
+// Time the link takes to serialize n bytes
+func (p Profile) serialization(n int) time.Duration {
+	if p.Bandwidth == 0 {
+		return 0
+	}
+	return time.Duration(int64(n) * 8 * int64(time.Second) / p.Bandwidth)
+}
+
+func (c *Conn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return 0, c.err
+	}
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+
+	for off := 0; off < len(b); off += MSS {
+		now := time.Now()
+		end := off + MSS
+		if end > len(b) {
+			end = len(b)
+		}
+		s := segment{data: append([]byte(nil), b[off:end]...)}
+
+		sent := now
+		if c.linkFree.After(sent) {
+			sent = c.linkFree
+		}
+		sent = sent.Add(c.profile.serialization(len(s.data)))
+		c.linkFree = sent
+
+		s.deliver = sent.Add(c.profile.delay(c.rand))
+		if c.profile.Loss > 0 && c.rand.Float64() < c.profile.Loss {
+			s.deliver = s.deliver.Add(c.profile.rto())
+		}
+
+		// A lost or jittered segment holds the following ones back
+		if s.deliver.Before(c.lastDelivery) {
+			s.deliver = c.lastDelivery
+		}
+		c.lastDelivery = s.deliver
+
+		c.queue = append(c.queue, s)
+		c.cond.Signal()
+
+		// Wait for the link to serialize the segments beyond the send buffer before writing the next one
+		if wait := time.Until(sent) - c.profile.serialization(sendBuffer); wait > 0 {
+			c.mu.Unlock()
+			time.Sleep(wait)
+			c.mu.Lock()
+
+			if c.err != nil {
+				return end, c.err
+			}
+			if c.closed {
+				return end, net.ErrClosed
+			}
+		}
+	}
+
+	return len(b), nil
+}
+
+// Writes the queued segments to the underlying connection once they are due
+func (c *Conn) deliver() {
+	defer close(c.done)
+
+	for {
+		c.mu.Lock()
+		for len(c.queue) == 0 && !c.closed {
+			c.cond.Wait()
+		}
+		if len(c.queue) == 0 {
+			c.mu.Unlock()
+			return
+		}
+		s := c.queue[0]
+		c.queue = c.queue[1:]
+		c.mu.Unlock()
+
+		time.Sleep(time.Until(s.deliver))
+
+		if _, err := c.Conn.Write(s.data); err != nil {
+			c.mu.Lock()
+			c.err = err
+			c.queue = nil
+			c.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Closes the connection once the queued segments are delivered, or closeTimeout after they are due if the peer
+// does not read them
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return net.ErrClosed
+	}
+	c.closed = true
+	c.cond.Signal()
+	deadline := c.lastDelivery
+	c.mu.Unlock()
+
+	if now := time.Now(); deadline.Before(now) {
+		deadline = now
+	}
+	c.Conn.SetWriteDeadline(deadline.Add(closeTimeout))
+
+	<-c.done
+	return c.Conn.Close()
+}
+
+type listener struct {
+	net.Listener
+	profile Profile
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.profile.Conn(c), nil
+}
+
+// Wraps ln so that the accepted connections are over the link of the profile. ln is returned as it is if the
+// profile does not emulate a link.
+func (p Profile) Listener(ln net.Listener) net.Listener {
+	if !p.Enabled() {
+		return ln
+	}
+	return &listener{Listener: ln, profile: p}
+}
@@ -0,0 +1,179 @@
+// Package netem emulates network links in process, wrapping the connections of the clients and servers to
+// delay, throttle and drop the segments they write.
+package netem
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum segment size of the emulated links, in bytes
+const MSS = 1460
+
+// TCP initial congestion window: the segments a sender may write before the first acknowledgment
+const DefaultInitCwnd = 10
+
+// Retransmission delay of a lost segment, if not set by the profile
+const DefaultRTO = 200 * time.Millisecond
+
+// Characteristics of an emulated link. Each side of a connection applies the profile to the segments it
+// writes, so the round trip time is twice Delay when both sides use the same profile.
+type Profile struct {
+	// Preset name, or empty for a custom profile
+	Name string `json:"name,omitempty"`
+
+	// One way delay and its uniform random variation, in both directions
+	Delay  time.Duration `json:"delay,omitempty"`
+	Jitter time.Duration `json:"jitter,omitempty"`
+
+	// Bandwidth in bits per second, or 0 for no limit
+	Bandwidth int64 `json:"bandwidth,omitempty"`
+
+	// Probability of losing a segment, which is then delivered after the retransmission delay RTO
+	Loss float64       `json:"loss,omitempty"`
+	RTO  time.Duration `json:"rto,omitempty"`
+}
+
+// Named link profiles
+var Presets = map[string]Profile{
+	"lan":       {Name: "lan", Delay: 250 * time.Microsecond, Jitter: 50 * time.Microsecond, Bandwidth: 1000000000},
+	"broadband": {Name: "broadband", Delay: 10 * time.Millisecond, Jitter: 1 * time.Millisecond, Bandwidth: 50000000, Loss: 0.0001},
+	"lte":       {Name: "lte", Delay: 35 * time.Millisecond, Jitter: 8 * time.Millisecond, Bandwidth: 10000000, Loss: 0.001, RTO: 250 * time.Millisecond},
+	"3g":        {Name: "3g", Delay: 100 * time.Millisecond, Jitter: 20 * time.Millisecond, Bandwidth: 2000000, Loss: 0.01, RTO: 400 * time.Millisecond},
+	"satellite": {Name: "satellite", Delay: 300 * time.Millisecond, Jitter: 10 * time.Millisecond, Bandwidth: 10000000, Loss: 0.005, RTO: 1200 * time.Millisecond},
+}
+
+// Names of the presets, sorted
+func PresetNames() []string {
+	var names []string
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parses a link profile: "none", a preset name, a comma or semicolon separated list of delay, jitter, bandwidth,
+// loss and rto settings (e.g. "delay=20ms,bandwidth=10mbit,loss=0.01"), or a preset followed by the settings it
+// overrides (e.g. "lte,loss=0.02"). Bandwidths accept the kbit, mbit and gbit suffixes.
+func Parse(spec string) (Profile, error) {
+	var p Profile
+
+	// The profiles recorded in the results files separate the settings with semicolons
+	spec = strings.ReplaceAll(strings.TrimSpace(strings.ToLower(spec)), ";", ",")
+	if spec == "" || spec == "none" {
+		return p, nil
+	}
+
+	for i, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+
+		key, value, ok := cut(item, "=")
+		if !ok {
+			preset, found := Presets[item]
+			if i > 0 || !found {
+				return Profile{}, fmt.Errorf("unknown link preset %q (presets: %s)", item, strings.Join(PresetNames(), ", "))
+			}
+			p = preset
+			continue
+		}
+
+		// A preset with overridden settings is a custom profile
+		p.Name = ""
+
+		var err error
+		switch key {
+		case "delay":
+			p.Delay, err = time.ParseDuration(value)
+		case "jitter":
+			p.Jitter, err = time.ParseDuration(value)
+		case "rto":
+			p.RTO, err = time.ParseDuration(value)
+		case "bandwidth":
+			p.Bandwidth, err = parseBandwidth(value)
+		case "loss":
+			p.Loss, err = strconv.ParseFloat(value, 64)
+			if err == nil && (p.Loss < 0 || p.Loss >= 1) {
+				err = fmt.Errorf("loss must be in [0, 1)")
+			}
+		default:
+			return Profile{}, fmt.Errorf("unknown link setting %q", key)
+		}
+		if err != nil {
+			return Profile{}, fmt.Errorf("link setting %s: %v", key, err)
+		}
+	}
+
+	if p.Delay < 0 || p.Jitter < 0 || p.RTO < 0 || p.Bandwidth < 0 {
+		return Profile{}, fmt.Errorf("link settings must not be negative")
+	}
+	if p.Jitter > p.Delay {
+		return Profile{}, fmt.Errorf("link jitter %v is larger than the delay %v", p.Jitter, p.Delay)
+	}
+
+	return p, nil
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func parseBandwidth(value string) (int64, error) {
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"kbit": 1000, "mbit": 1000000, "gbit": 1000000000} {
+		if strings.HasSuffix(value, suffix) {
+			value = strings.TrimSuffix(value, suffix)
+			multiplier = m
+			break
+		}
+	}
+
+	bw, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(bw * float64(multiplier)), nil
+}
+
+// Reports whether the profile emulates a link, instead of using the connections as they are
+func (p Profile) Enabled() bool {
+	return p.Delay > 0 || p.Bandwidth > 0 || p.Loss > 0
+}
+
+// Retransmission delay of the lost segments
+func (p Profile) rto() time.Duration {
+	if p.RTO > 0 {
+		return p.RTO
+	}
+	return DefaultRTO
+}
+
+// Name of the profile recorded in the results files: the preset name, the settings of a custom profile, or none
+func (p Profile) String() string {
+	if !p.Enabled() {
+		return "none"
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+
+	settings := []string{"delay=" + p.Delay.String()}
+	if p.Jitter > 0 {
+		settings = append(settings, "jitter="+p.Jitter.String())
+	}
+	if p.Bandwidth > 0 {
+		settings = append(settings, fmt.Sprintf("bandwidth=%d", p.Bandwidth))
+	}
+	if p.Loss > 0 {
+		settings = append(settings, "loss="+strconv.FormatFloat(p.Loss, 'g', -1, 64), "rto="+p.rto().String())
+	}
+
+	// Results files are comma separated
+	return strings.Join(settings, ";")
+}
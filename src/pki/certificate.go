@@ -0,0 +1,361 @@
+// Package pki creates the certificates and the Certificate Authority chains used in the tests, and reads and
+// writes the Root CA files.
+package pki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/kem"
+	"crypto/liboqs_sig"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"tls_tests/src/algorithms"
+)
+
+// Root CA of the certificate chains: a Hybrid Root CA of a family, read from root_ca/, or a classic Root CA
+// read from PEM files
+type RootCA struct {
+	HybridFamily string
+	CertFile     string
+	KeyFile      string
+
+	// NIST level of the Hybrid Root CA. If 0, DefaultRootLevel is used
+	Level int
+}
+
+// NIST level of the Hybrid Root CA if RootCA.Level is not set
+const DefaultRootLevel = 5
+
+// Reads the Root CA certificate and private key
+func (r RootCA) Load() (rootCertX509 *x509.Certificate, rootPriv interface{}) {
+	if r.CertFile != "" {
+		return ReadClassicRoot(r.CertFile, r.KeyFile)
+	}
+	return ReadHybridRoot(r.HybridFamily, r.level())
+}
+
+func (r RootCA) level() int {
+	if r.Level == 0 {
+		return DefaultRootLevel
+	}
+	return r.Level
+}
+
+// Name of the Root CA algorithm: the hybrid algorithm of the family in the level, or the one of the classic
+// Root CA files written by the root command, such as ECDSA_P256
+func (r RootCA) Name() string {
+	if r.CertFile != "" {
+		name := strings.TrimSuffix(filepath.Base(r.CertFile), ".pem")
+		return strings.TrimSuffix(strings.TrimPrefix(name, "classic_root_ca_"), "_cert")
+	}
+
+	algo, err := algorithms.Default.Find(algorithms.Signature, algorithms.Hybrid, r.HybridFamily, r.level())
+	if err != nil {
+		return r.HybridFamily
+	}
+	return algo.Name
+}
+
+// Default number of Intermediate CAs of a chain
+const DefaultChainDepth = 1
+
+// Intermediate CAs between the Root CA and the client and server certificates
+type ChainShape struct {
+	// Number of Intermediate CAs. With no Intermediate CA, the certificates are issued by the Root CA
+	Depth int
+
+	// Signature algorithms of the Intermediate CAs, from the one issued by the Root CA down, or a single one for
+	// all of them. If empty, the Intermediate CAs use ECDSA in classic chains and Dilithium otherwise, in the
+	// level of the chain.
+	Algorithms []string
+}
+
+// Checks the depth and the intermediate algorithms of the shape in classic or post-quantum chains
+func (s ChainShape) Check(classic bool) error {
+	if s.Depth < 0 {
+		return errors.New("negative chain depth")
+	}
+	if s.Depth == 0 && len(s.Algorithms) > 0 {
+		return errors.New("intermediate algorithms for a chain without Intermediate CA")
+	}
+	if len(s.Algorithms) > 1 && len(s.Algorithms) != s.Depth {
+		return fmt.Errorf("%d intermediate algorithms for a chain depth of %d", len(s.Algorithms), s.Depth)
+	}
+	for _, name := range s.Algorithms {
+		if _, err := intermediateAlgorithm(name, classic); err != nil {
+			return fmt.Errorf("%v: %s", err, name)
+		}
+	}
+	return nil
+}
+
+// Algorithm name of the i-th Intermediate CA, or "" for the default one
+func (s ChainShape) algorithmName(i int) string {
+	if len(s.Algorithms) == 0 {
+		return ""
+	}
+	if len(s.Algorithms) == 1 {
+		return s.Algorithms[0]
+	}
+	return s.Algorithms[i]
+}
+
+// Describes the chain of the Root CA from the root down, e.g. "P521_Dilithium5>P256_Dilithium2", where "default"
+// stands for the Intermediate CAs in the level of the chain
+func (s ChainShape) Describe(root RootCA) string {
+	names := []string{root.Name()}
+	for i := 0; i < s.Depth; i++ {
+		name := s.algorithmName(i)
+		if name == "" {
+			name = "default"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ">")
+}
+
+func intermediateAlgorithm(name string, classic bool) (interface{}, error) {
+	if classic {
+		return algorithms.NameToClassicSigAlgo(name)
+	}
+	return algorithms.NameToSigID(name)
+}
+
+// Default algorithm of the Intermediate CAs of a chain in securityLevel: ECDSA in classic chains and Dilithium
+// otherwise
+func defaultIntermediateAlgorithm(classic bool, securityLevel int) interface{} {
+	if classic {
+		switch securityLevel {
+		case 1:
+			return elliptic.P256()
+		case 3:
+			return elliptic.P384()
+		case 5:
+			return elliptic.P521()
+		}
+	} else {
+		switch securityLevel {
+		case 1:
+			return liboqs_sig.P256_Dilithium2
+		case 3:
+			return liboqs_sig.P384_Dilithium3
+		case 5:
+			return liboqs_sig.P521_Dilithium5
+		}
+	}
+	panic("Unknown security level")
+}
+
+// Certificate Authority chain (Root CA and Intermediate CAs) issuing the client and server certificates
+type Chain struct {
+	Root *x509.Certificate
+
+	// Intermediate CAs, from the one issued by the Root CA down
+	Intermediates []*x509.Certificate
+
+	// CA issuing the client and server certificates: the last Intermediate CA, or the Root CA in chains without
+	// Intermediate CAs
+	IssuerCert *x509.Certificate
+	IssuerPriv interface{}
+}
+
+// Constructs a chain of the shape to be shared by several client and server configurations. The default
+// Intermediate CAs are in securityLevel.
+func NewChain(root RootCA, classic bool, shape ChainShape, securityLevel int) *Chain {
+	rootCertX509, rootPriv := root.Load()
+	chain := &Chain{Root: rootCertX509, IssuerCert: rootCertX509, IssuerPriv: rootPriv}
+
+	for i := 0; i < shape.Depth; i++ {
+		var intCAAlgo interface{}
+		if name := shape.algorithmName(i); name != "" {
+			var err error
+			intCAAlgo, err = intermediateAlgorithm(name, classic)
+			if err != nil {
+				panic(err)
+			}
+		} else {
+			intCAAlgo = defaultIntermediateAlgorithm(classic, securityLevel)
+		}
+
+		// Each Intermediate CA has its own subject, so that the path building of the verifier only tries its
+		// issuer at each level of the chain
+		name := fmt.Sprintf("Intermediate CA %d", i+1)
+		intCACertBytes, intCAPriv, err := CreateCertificate(intCAAlgo, chain.IssuerCert, chain.IssuerPriv, true, false, name, x509.KeyUsageCertSign, nil, "127.0.0.1")
+		if err != nil {
+			panic(err)
+		}
+
+		intCACert, err := x509.ParseCertificate(intCACertBytes)
+		if err != nil {
+			panic(err)
+		}
+
+		chain.Intermediates = append(chain.Intermediates, intCACert)
+		chain.IssuerCert, chain.IssuerPriv = intCACert, intCAPriv
+	}
+
+	return chain
+}
+
+// DER certificates of the Intermediate CAs in the order they follow the leaf certificate in the Certificate
+// message, from the issuer of the leaf up
+func (c *Chain) IntermediatesDER() [][]byte {
+	var der [][]byte
+	for i := len(c.Intermediates) - 1; i >= 0; i-- {
+		der = append(der, c.Intermediates[i].Raw)
+	}
+	return der
+}
+
+// Chains of a Root CA by level of their default Intermediate CAs, each one constructed once and shared by the
+// client and server configurations of a single process
+type Chains struct {
+	root    RootCA
+	classic bool
+	shape   ChainShape
+
+	mu      sync.Mutex
+	byLevel map[int]*Chain
+}
+
+func NewChains(root RootCA, classic bool, shape ChainShape) *Chains {
+	return &Chains{root: root, classic: classic, shape: shape, byLevel: make(map[int]*Chain)}
+}
+
+// Returns the chain whose default Intermediate CAs are in securityLevel, constructing it on first use
+func (c *Chains) Get(securityLevel int) *Chain {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	chain, ok := c.byLevel[securityLevel]
+	if !ok {
+		chain = NewChain(c.root, c.classic, c.shape, securityLevel)
+		c.byLevel[securityLevel] = chain
+	}
+	return chain
+}
+
+// Creates a certificate with the algorithm specified by pubkeyAlgo, signed by signer with signerPrivKey. peer is
+// the common name of the client, server or Intermediate CA certificate.
+func CreateCertificate(pubkeyAlgo interface{}, signer *x509.Certificate, signerPrivKey interface{}, isCA bool, isSelfSigned bool, peer string, keyUsage x509.KeyUsage, extKeyUsage []x509.ExtKeyUsage, hostName string) ([]byte, interface{}, error) {
+
+	var _validFor time.Duration
+
+	if isCA {
+		_validFor = 8760 * time.Hour // 1 year
+	} else {
+		_validFor = 240 * time.Hour // 10 days
+	}
+
+	//fix for testing remotely.
+	if hostName == "0.0.0.0" {
+		hostName = "34.116.197.232" //"34.116.206.139"
+	}
+
+	var _host string = hostName
+	var commonName string
+
+	var pub, priv interface{}
+	var err error
+
+	var certDERBytes []byte
+
+	if isCA {
+		if isSelfSigned {
+			commonName = "Root CA"
+		} else {
+			commonName = peer
+		}
+	} else {
+		commonName = peer
+	}
+
+	if curveID, ok := pubkeyAlgo.(tls.CurveID); ok { // Hybrid KEMTLS
+		kemID := kem.ID(curveID)
+
+		pub, priv, err = kem.GenerateKey(rand.Reader, kemID)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else if scheme, ok := pubkeyAlgo.(liboqs_sig.ID); ok { // Liboqs Hybrid Signature
+		pub, priv, err = liboqs_sig.GenerateKey(scheme)
+
+		if err != nil {
+			log.Fatalf("Failed to generate private key: %v", err)
+		}
+	} else if curve, ok := pubkeyAlgo.(elliptic.Curve); ok { // Classic ECDSA
+		ecdsaPriv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, priv = &ecdsaPriv.PublicKey, ecdsaPriv
+	} else if bits, ok := pubkeyAlgo.(algorithms.RSAKeySize); ok { // Classic RSA
+		rsaPriv, err := rsa.GenerateKey(rand.Reader, int(bits))
+		if err != nil {
+			return nil, nil, err
+		}
+		pub, priv = &rsaPriv.PublicKey, rsaPriv
+	}
+
+	notBefore := time.Now()
+	notAfter := notBefore.Add(_validFor)
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		log.Fatalf("Failed to generate serial number: %v", err)
+	}
+
+	var certTemplate x509.Certificate
+
+	certTemplate = x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		NotBefore: notBefore,
+		NotAfter:  notAfter,
+
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsage,
+		BasicConstraintsValid: true,
+	}
+
+	hosts := strings.Split(_host, ",")
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			certTemplate.IPAddresses = append(certTemplate.IPAddresses, ip)
+		} else {
+			certTemplate.DNSNames = append(certTemplate.DNSNames, h)
+		}
+	}
+
+	if isCA {
+		certTemplate.IsCA = true
+		certTemplate.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	if isSelfSigned {
+		certDERBytes, err = x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, pub, priv)
+	} else {
+		certDERBytes, err = x509.CreateCertificate(rand.Reader, &certTemplate, signer, pub, signerPrivKey)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certDERBytes, priv, nil
+}
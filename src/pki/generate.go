@@ -1,4 +1,4 @@
-package main
+package pki
 
 import (
 	"bufio"
@@ -7,22 +7,45 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/hex"
-	"flag"
+	"encoding/pem"
 	"log"
 	"os"
 	"strconv"
 )
 
-var (
-	rootAlgo = flag.String("algo", "P256", "Root CA Algorithm")
-)
+// Generates a classic Root CA, writing its certificate and PKCS#8 private key to PEM files
+func GenerateClassicRoot(rootAlgoName string, rootCAAlgo interface{}) {
+	rootKeyUsage := x509.KeyUsageCertSign
+
+	rootCACertBytes, rootCAPriv, err := CreateCertificate(rootCAAlgo, nil, nil, true, true, "server", rootKeyUsage, nil, "127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+
+	rootPrivBytes, err := x509.MarshalPKCS8PrivateKey(rootCAPriv)
+	if err != nil {
+		panic(err)
+	}
+
+	certFileName := "root_ca/classic_root_ca_" + rootAlgoName + "_cert.pem"
+	keyFileName := "root_ca/classic_root_ca_" + rootAlgoName + "_key.pem"
+
+	if err := os.WriteFile(certFileName, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootCACertBytes}), 0644); err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+
+	if err := os.WriteFile(keyFileName, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rootPrivBytes}), 0600); err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+}
 
-func generateHybridRoot(rootCAAlgo interface{}, curve elliptic.Curve) {
+// Generates a Hybrid Root CA, writing its data to a text file that is read by ReadHybridRoot
+func GenerateHybridRoot(rootAlgoName string, rootCAAlgo interface{}, curve elliptic.Curve) {
 	/* ---------------------------- Root Certificate ---------------------------- */
 
 	rootKeyUsage := x509.KeyUsageCertSign
 
-	rootCACertBytes, rootCAPriv, err := createCertificate(rootCAAlgo, nil, nil, true, true, "server", rootKeyUsage, nil, "127.0.0.1")
+	rootCACertBytes, rootCAPriv, err := CreateCertificate(rootCAAlgo, nil, nil, true, true, "server", rootKeyUsage, nil, "127.0.0.1")
 	if err != nil {
 		panic(err)
 	}
@@ -72,7 +95,7 @@ func generateHybridRoot(rootCAAlgo interface{}, curve elliptic.Curve) {
 	sigIDString := strconv.FormatInt(int64(priv.SigId), 16)
 
 	rootCAData := []string{sigIDString, curveString, hex.EncodeToString(oidBytes), hex.EncodeToString(rootPrivBytes), hex.EncodeToString(privPqc), hex.EncodeToString(classicPubBytes), hex.EncodeToString(pubPqc), hex.EncodeToString(rootCACertBytes)}
-	fileName := "root_ca/hybrid_root_ca_" + *rootAlgo + ".txt"
+	fileName := "root_ca/hybrid_root_ca_" + rootAlgoName + ".txt"
 	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		log.Fatalf("failed creating file: %s", err)
@@ -87,16 +110,3 @@ func generateHybridRoot(rootCAAlgo interface{}, curve elliptic.Curve) {
 	datawriter.Flush()
 	file.Close()
 }
-
-func main() {
-
-	flag.Parse()
-	
-	rootLiboqsID, err := nameToSigID(*rootAlgo)
-	if err != nil {
-		panic(err)
-	}
-		
-	curve, _ := liboqs_sig.ClassicFromSig(rootLiboqsID)
-	generateHybridRoot(rootLiboqsID, curve)
-}
@@ -1,8 +1,10 @@
-package main
+package pki
 
 import (
+	"crypto"
 	"crypto/liboqs_sig"
 	"crypto/x509"
+	"encoding/pem"
 	"log"
 
 	// For readHybridRootFile
@@ -15,31 +17,27 @@ import (
 	"crypto/elliptic"
 	"encoding/asn1"
 	"encoding/hex"
+
+	"tls_tests/src/algorithms"
 )
 
-func constructHybridRoot(rootFamily string, securityLevel int) (*x509.Certificate, *liboqs_sig.PrivateKey) {
+// Reads the Hybrid Root CA of the family in the security level from its file in root_ca/
+func ReadHybridRoot(rootFamily string, securityLevel int) (*x509.Certificate, *liboqs_sig.PrivateKey) {
 
 	/* ------------------------------ Reading file ------------------------------ */
 	var rootData []string
 	var rootFileName string
-	var algList []string
-
-	dilithiumAlg := []string{"P256_Dilithium2", "", "P384_Dilithium3", "", "P521_Dilithium5"}
-	falconAlg := []string{"P256_Falcon512", "", "P256_Falcon512", "", "P521_Falcon1024"}
 
-	if rootFamily == "dilithium" {
-		algList = dilithiumAlg
-	} else if rootFamily == "falcon" {
-		algList = falconAlg
-	} else {
-		panic("Unknown Root CA algorithm family")
+	if securityLevel != 1 && securityLevel != 3 && securityLevel != 5 {
+		panic("Unknown security level")
 	}
 
-	if securityLevel == 1 || securityLevel == 3 || securityLevel == 5 {
-		rootFileName = "root_ca/hybrid_root_ca_" + algList[securityLevel-1] + ".txt"
-	} else {
-		panic("Unknown security level")
+	// Families without an algorithm in the security level use the one of the closest lower level
+	rootAlgo, err := algorithms.Default.Find(algorithms.Signature, algorithms.Hybrid, rootFamily, securityLevel)
+	if err != nil {
+		panic("Unknown Root CA algorithm family")
 	}
+	rootFileName = "root_ca/hybrid_root_ca_" + rootAlgo.Name + ".txt"
 
 	file, err := os.Open(rootFileName)
 	if err != nil {
@@ -147,3 +145,58 @@ func constructHybridRoot(rootFamily string, securityLevel int) (*x509.Certificat
 
 	return rootCACert, rootCAPriv
 }
+
+// Reads a classic Root CA from its PEM encoded certificate and private key files
+func ReadClassicRoot(certFile, keyFile string) (*x509.Certificate, crypto.Signer) {
+	if keyFile == "" {
+		log.Fatal("The Root CA private key file (-rootkey) must be supplied along with its certificate (-rootcert)")
+	}
+
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		panic("Root CA certificate file does not contain a PEM encoded certificate")
+	}
+
+	rootCACert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		panic(err)
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		panic("Root CA private key file does not contain a PEM encoded private key")
+	}
+
+	var rootCAPriv interface{}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		rootCAPriv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		rootCAPriv, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		rootCAPriv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		panic("Unknown Root CA private key PEM type: " + block.Type)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	signer, ok := rootCAPriv.(crypto.Signer)
+	if !ok {
+		panic("Root CA private key is not a signer")
+	}
+
+	return rootCACert, signer
+}
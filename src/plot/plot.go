@@ -1,4 +1,5 @@
-package main
+// Package plot draws the graphs of the KEMTLS handshake results in graphs/.
+package plot
 
 import (
 	"image/color"
@@ -10,10 +11,13 @@ import (
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
 	"github.com/go-echarts/go-echarts/v2/opts"
-	"gonum.org/v1/plot"
+	gplot "gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
 	"math"
+
+	"tls_tests/src/algorithms"
+	"tls_tests/src/stats"
 )
 
 const (
@@ -23,38 +27,43 @@ const (
 	metricWKEMCtTime     = "Avg Write KEM Ciphertext - Client (ms)"
 )
 
+// Metrics of the bar graphs drawn by Genbar
+var Metrics = []string{metricCompletionTime, metricCHTime, metricPSHTime, metricWKEMCtTime}
+
 //Dimgray
 var barsGraphColor = color.RGBA{R: 105, G: 105, B: 105, A: 255}
 var hybridBarsGraphColor = color.RGBA{R: 0, G: 0, B: 0, A: 255}
 
 
-//Security levels
-var nistLevels = [][]string{
-	{"Kyber512", "LightSaber_KEM", "NTRU_HPS_2048_509"},
-	{"P256_Kyber512", "P256_LightSaber_KEM", "P256_NTRU_HPS_2048_509"},
-	{"Kyber768", "Saber_KEM", "NTRU_HPS_2048_677", "NTRU_HRSS_701"},
-	{"P384_Kyber768", "P384_Saber_KEM", "P384_NTRU_HPS_2048_677", "P384_NTRU_HRSS_701"},
-	{"Kyber1024", "FireSaber_KEM", "NTRU_HPS_4096_821", "NTRU_HPS_4096_1229","NTRU_HRSS_1373"},
-	{"P521_Kyber1024", "P521_FireSaber_KEM", "P521_NTRU_HPS_4096_821", "P521_NTRU_HPS_4096_1229", "P521_NTRU_HRSS_1373"},
-}
-var nistLevelsTogether = [][]string{
-	{"Kyber512", "P256_Kyber512", "LightSaber_KEM", "P256_LightSaber_KEM", "NTRU_HPS_2048_509", "P256_NTRU_HPS_2048_509"},
-	{"Kyber768", "P384_Kyber768", "Saber_KEM", "P384_Saber_KEM", "NTRU_HPS_2048_677", "P384_NTRU_HPS_2048_677", "NTRU_HRSS_701", "P384_NTRU_HRSS_701"},
-	{"Kyber1024", "P521_Kyber1024", "FireSaber_KEM", "P521_FireSaber_KEM", "NTRU_HPS_4096_821", "P521_NTRU_HPS_4096_821", "NTRU_HPS_4096_1229",  "P521_NTRU_HPS_4096_1229","NTRU_HRSS_1373",   "P521_NTRU_HRSS_1373"},	
+//Security levels: pure and hybrid KEMs of each NIST level, in alternate rows
+var nistLevels = registryNISTLevels(false)
+var nistLevelsTogether = registryNISTLevels(true)
+
+func registryNISTLevels(together bool) (rows [][]string) {
+	for _, level := range []int{1, 3, 5} {
+		pure := algorithms.Default.NamesInLevel(algorithms.KEM, algorithms.Pure, level)
+		hybrid := algorithms.Default.NamesInLevel(algorithms.KEM, algorithms.Hybrid, level)
+		if together {
+			rows = append(rows, append(pure, hybrid...))
+		} else {
+			rows = append(rows, pure, hybrid)
+		}
+	}
+	return rows
 }
 
 
-func resultsToArray(results []KEMTLSClientResultsInfo, row []string) (rArrayNames []string, rArrayTotalTime plotter.Values,
+func resultsToArray(results []stats.KEMTLSClientResultsInfo, row []string) (rArrayNames []string, rArrayTotalTime plotter.Values,
 	rArrayCHello plotter.Values, rArrayPSHello plotter.Values, rArrayWKEMCt plotter.Values) {
 
 	for _, algo := range row {		
 		for _, r := range results {
-			if algo == r.kexName {
-				rArrayNames = append(rArrayNames, r.kexName)
-				rArrayTotalTime = append(rArrayTotalTime, r.avgTotalTime)
-				rArrayCHello = append(rArrayCHello, r.avgWriteClientHello)
-				rArrayPSHello = append(rArrayPSHello, r.avgProcessServerHello)
-				rArrayWKEMCt = append(rArrayWKEMCt, r.avgWriteKEMCiphertext)
+			if algo == r.KEXName {
+				rArrayNames = append(rArrayNames, r.KEXName)
+				rArrayTotalTime = append(rArrayTotalTime, r.AvgTotalTime)
+				rArrayCHello = append(rArrayCHello, r.AvgWriteClientHello)
+				rArrayPSHello = append(rArrayPSHello, r.AvgProcessServerHello)
+				rArrayWKEMCt = append(rArrayWKEMCt, r.AvgWriteKEMCiphertext)
 			}
 		}
 	}
@@ -79,7 +88,7 @@ func switcherType(metric string, rArrayTotalTime plotter.Values, rArrayCHello pl
 /*
  * Bar chart from gonum/plot
  */
-func genbar(results []KEMTLSClientResultsInfo, metric string) {	
+func Genbar(results []stats.KEMTLSClientResultsInfo, metric string) {	
 	//for i, row := range nistLevels{
 	nistLevel := 1
 	for i := 0; i < 6 ; i+=2 {
@@ -93,11 +102,19 @@ func genbar(results []KEMTLSClientResultsInfo, metric string) {
 		}*/
 		fmt.Println("Saving Bar graphs level:" +  fmt.Sprintf("%d", nistLevel) + "/"+strings.ReplaceAll(metric, " ", "")+"...")		
 		names, resultsTotalTime, resultsCH, resultsPSH, resultsWKEMCt := resultsToArray(results,nistLevels[i])
+		hybridNames, _, _, _, _ := resultsToArray(results,nistLevels[i+1])
+
+		// The bars compare the PQC-only and hybrid results of the level
+		if len(names) == 0 || len(hybridNames) == 0 {
+			fmt.Println("Skipping level " + fmt.Sprintf("%d", nistLevel) + ": no PQC-only or hybrid results")
+			nistLevel += 2
+			continue
+		}
 		
 		//select desired metric
 		groupBar := switcherType(metric, resultsTotalTime, resultsCH, resultsPSH, resultsWKEMCt)
 
-		p := plot.New()
+		p := gplot.New()
 		p.X.Min = 0
 		p.Y.Min = 0
 		p.Y.Label.Text = metric
@@ -147,10 +164,10 @@ func genbar(results []KEMTLSClientResultsInfo, metric string) {
 /*
  * Boxplot chart from gonum/plot
  */
-func boxplot(names []string, vals []plotter.Values, hs int) {
+func Boxplot(names []string, vals []plotter.Values, hs int) {
 
 	// Create the plot and set its title and axis label.
-	p := plot.New()
+	p := gplot.New()
 
 	fmt.Println("Saving Boxplot for hybrid KEMTLS...")
 
@@ -195,7 +212,7 @@ func boxplot(names []string, vals []plotter.Values, hs int) {
 
 //get data for the plot
 //datatype is PQC-only or Hybrid
-func getBarItems(results []KEMTLSClientResultsInfo, datatype string,selection string) (items []opts.BarData, names []string) {
+func getBarItems(results []stats.KEMTLSClientResultsInfo, datatype string,selection string) (items []opts.BarData, names []string) {
 
 	items = make([]opts.BarData, 0)
 
@@ -204,16 +221,16 @@ func getBarItems(results []KEMTLSClientResultsInfo, datatype string,selection st
 
 	for _, r1 := range results {
 		if datatype == "PQC-only" {
-			if !re.MatchString(r1.kexName) {
-				items = append(items, opts.BarData{Name: r1.kexName,
-					Value: math.Round(r1.avgTotalTime*100)/100 })
-				names = append(names, r1.kexName)
+			if !re.MatchString(r1.KEXName) {
+				items = append(items, opts.BarData{Name: r1.KEXName,
+					Value: math.Round(r1.AvgTotalTime*100)/100 })
+				names = append(names, r1.KEXName)
 			}
 		} else {
-			if re.MatchString(r1.kexName) {
-				resultStr := reReplace.ReplaceAllString(r1.kexName,"")
+			if re.MatchString(r1.KEXName) {
+				resultStr := reReplace.ReplaceAllString(r1.KEXName,"")
 				items = append(items, opts.BarData{Name: resultStr,
-					Value: math.Round(r1.avgTotalTime*100)/100 })
+					Value: math.Round(r1.AvgTotalTime*100)/100 })
 			}
 		}
 	}
@@ -221,7 +238,7 @@ func getBarItems(results []KEMTLSClientResultsInfo, datatype string,selection st
 	return items, names
 }
 //selection is "All" or "L1"
-func barMarkLines(results []KEMTLSClientResultsInfo, selection string) { //*charts.Bar {
+func BarMarkLines(results []stats.KEMTLSClientResultsInfo, selection string) { //*charts.Bar {
 	bar := charts.NewBar()
 
 	dataPQC, pqcNames := getBarItems(results, "PQC-only", selection)
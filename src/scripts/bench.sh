@@ -1,3 +1,3 @@
 cd ..
-go run bench.go common.go parse_hybrid_root.go stats_kemtls.go stats_tls.go \
+go run ./cmd/tlstests bench \
 -reps 100
@@ -3,5 +3,5 @@ source config.sh
 
 cd ..
 
-go run launch_client.go common.go parse_hybrid_root.go stats_kemtls.go stats_tls.go plot_functions.go \
+go run ./cmd/tlstests client \
 ${MUTUAL_FLAGS}
@@ -1,6 +1,6 @@
 #!/bin/bash
 
-# The following flags are mutual for the client and the server
+# The following flags are mutual for the client and the standalone (-sync=false) server
 # -pqtls 
 # -clientauth 
 # -handshakes 
@@ -14,3 +14,6 @@ CLIENT_IP=127.0.0.1
 SERVER_IP=127.0.0.1
 
 MUTUAL_FLAGS="-ipclient ${CLIENT_IP} -ipserver ${SERVER_IP} -handshakes 5 -hybridroot dilithium"
+
+# The load command takes the server from -u and does not measure handshakes
+LOAD_FLAGS="-ipclient ${CLIENT_IP} -hybridroot dilithium"
@@ -1,11 +1,19 @@
 #!/bin/bash
 
 HYBRID_ALGS=(P256_Dilithium2 P384_Dilithium3 P521_Dilithium5)
+CLASSIC_ALGS=(ECDSA_P521)
 
 cd ..
 
 for algo in ${HYBRID_ALGS[*]}
 do
-go run generate_root.go common.go stats_tls.go stats_kemtls.go plot_functions.go parse_hybrid_root.go \
+go run ./cmd/tlstests root \
 -algo ${algo}
-done
\ No newline at end of file
+done
+
+for algo in ${CLASSIC_ALGS[*]}
+do
+go run ./cmd/tlstests root \
+-algo ${algo} \
+-classic
+done
@@ -1,6 +1,7 @@
 #!/bin/bash
 source config.sh
 
+# Standalone HTTP server, the load test must be run with -sync=false
 # HTTP server exclusive flags
 # -http
 # -kex
@@ -8,7 +9,8 @@ source config.sh
 
 cd ..
 
-go run launch_servers.go common.go parse_hybrid_root.go stats_tls.go stats_kemtls.go plot_functions.go \
+go run ./cmd/tlstests server \
+-sync=false \
 -http \
 -kex P256_HQC_128 \
 -authserver P256_HQC_128 \
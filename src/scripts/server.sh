@@ -3,5 +3,5 @@ source config.sh
 
 cd ..
 
-go run launch_servers.go common.go parse_hybrid_root.go stats_tls.go stats_kemtls.go plot_functions.go \
-${MUTUAL_FLAGS}
\ No newline at end of file
+# The experiment is negotiated by the client through the control protocol
+go run ./cmd/tlstests server
@@ -1,22 +1,9 @@
 # Configuration script for the server.sh and client.sh scripts.
-# Configure here the experiments parameters
+# The experiments parameters are configured in the spec file
 
-NUM_HANDSHAKES=1000
-BENCHMARK_REPS=1000
-HYBRID_ROOT=dilithium
+EXPERIMENT_SPEC=scripts/tcc_experiments/experiments.json
 
-# Load test experiments parameters
-NUM_CLIENTS_LIST=(128 256 512)
-LOAD_TEST_SECONDS=360
+BENCHMARK_REPS=1000
 
 # Experiments enabled
 EXP_BENCHMARK=true
-EXP_HYBRID_KEMTLS=true
-EXP_HYBRID_PQTLS=true
-EXP_HYBRID_KEMTLS_PDK=true
-EXP_HYBRID_KEMTLS_PDK_CLASSIC_MCELIECE=true
-EXP_HYBRID_PQTLS_CACHED_CERTS=true
-EXP_HYBRID_KEMTLS_LOAD_TEST=true
-EXP_HYBRID_KEMTLS_PDK_LOAD_TEST=true
-EXP_HYBRID_PQTLS_LOAD_TEST=true
-EXP_HYBRID_PQTLS_CACHED_CERT_LOAD_TEST=true
\ No newline at end of file
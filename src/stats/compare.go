@@ -0,0 +1,316 @@
+package stats
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Comparison of a timing of the handshakes of a pair of algorithms between a base and a new result set
+type Comparison struct {
+	KEXName  string
+	AuthName string
+	Metric   string
+	Setup    Setup
+
+	// Measurements, means and medians of the base and the new timings, in milliseconds
+	NBase, NNew           int
+	MeanBase, MeanNew     float64
+	MedianBase, MedianNew float64
+
+	// Change of the mean relative to the base mean, e.g. 0.05 for 5% slower, and its 95% confidence interval from
+	// the Welch t interval of the difference of the means
+	Change, ChangeLow, ChangeHigh float64
+
+	// Two-sided p-values of the Welch t test, of a difference of the means, and of the Mann-Whitney U test, of a
+	// shift of the distributions
+	WelchP       float64
+	MannWhitneyP float64
+}
+
+// Reports whether the new timings are slower than the base ones by more than threshold, relative to the base
+// mean, and the Mann-Whitney U test finds the difference significant at level alpha
+func (c Comparison) Regression(threshold, alpha float64) bool {
+	return c.Change > threshold && c.MannWhitneyP < alpha
+}
+
+// Compares the timings of each pair of algorithms and setup measured in both result sets, read from client results
+// files with the given headers. The pairs measured in only one of the sets are skipped with a warning.
+func Compare(baseHeader []string, base []Measurements, currentHeader []string, current []Measurements) ([]Comparison, error) {
+	metrics, err := timingMetrics(baseHeader, currentHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := func(m Measurements) string {
+		return strings.Join([]string{m.KEXName, m.AuthName, m.Handshake, m.Chain, m.KeyShare, m.Link}, ",")
+	}
+
+	index := make(map[string]Measurements)
+	for _, m := range current {
+		index[key(m)] = m
+	}
+
+	var comparisons []Comparison
+	matched := make(map[string]bool)
+	for _, b := range base {
+		n, ok := index[key(b)]
+		if !ok {
+			log.Printf("%s %s (%s) only in the base results, skipped\n", b.KEXName, b.AuthName, setupString(b.Setup))
+			continue
+		}
+		matched[key(b)] = true
+
+		for i, metric := range metrics {
+			c := compareTimings(b.Timings[i], n.Timings[i])
+			c.KEXName, c.AuthName, c.Metric, c.Setup = b.KEXName, b.AuthName, metric, b.Setup
+			comparisons = append(comparisons, c)
+		}
+	}
+
+	for _, n := range current {
+		if !matched[key(n)] {
+			log.Printf("%s %s (%s) only in the new results, skipped\n", n.KEXName, n.AuthName, setupString(n.Setup))
+		}
+	}
+
+	return comparisons, nil
+}
+
+// Metrics of the timing columns of two client results files, which must be the same
+func timingMetrics(baseHeader, currentHeader []string) ([]string, error) {
+	var metrics [2][]string
+	for h, header := range [][]string{baseHeader, currentHeader} {
+		for _, column := range header[2:] {
+			if column == "cpuUser" || column == "kexLevel" || column == "link" {
+				break
+			}
+			metrics[h] = append(metrics[h], MetricName(column))
+		}
+	}
+
+	if strings.Join(metrics[0], ",") != strings.Join(metrics[1], ",") {
+		return nil, fmt.Errorf("the timings of the base results (%s) differ from the new ones (%s)",
+			strings.Join(metrics[0], ", "), strings.Join(metrics[1], ", "))
+	}
+	return metrics[0], nil
+}
+
+func compareTimings(base, current []float64) Comparison {
+	var c Comparison
+
+	c.NBase, c.NNew = len(base), len(current)
+	c.MeanBase, c.MeanNew = mean(base), mean(current)
+	c.MedianBase, c.MedianNew = Percentile(base, 50), Percentile(current, 50)
+
+	diff, low, high, p := welch(base, current)
+	c.WelchP = p
+	c.MannWhitneyP = mannWhitney(base, current)
+
+	switch {
+	case c.MeanBase != 0:
+		c.Change, c.ChangeLow, c.ChangeHigh = diff/c.MeanBase, low/c.MeanBase, high/c.MeanBase
+	case diff != 0:
+		c.Change, c.ChangeLow, c.ChangeHigh = math.Inf(1), math.Inf(1), math.Inf(1)
+	}
+
+	return c
+}
+
+func mean(measurements []float64) float64 {
+	if len(measurements) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, m := range measurements {
+		sum += m
+	}
+	return sum / float64(len(measurements))
+}
+
+func variance(measurements []float64) float64 {
+	if len(measurements) < 2 {
+		return 0
+	}
+	avg := mean(measurements)
+	var sum float64
+	for _, m := range measurements {
+		sum += math.Pow(m-avg, 2)
+	}
+	return sum / float64(len(measurements)-1)
+}
+
+// Welch t test of the difference of the means of current and base, returning the difference, its 95% confidence
+// interval and the two-sided p-value
+func welch(base, current []float64) (diff, low, high, p float64) {
+	diff = mean(current) - mean(base)
+	if len(base) < 2 || len(current) < 2 {
+		return diff, diff, diff, 1
+	}
+
+	vb, vc := variance(base)/float64(len(base)), variance(current)/float64(len(current))
+	se := math.Sqrt(vb + vc)
+	if se == 0 {
+		p = 1
+		if diff != 0 {
+			p = 0
+		}
+		return diff, diff, diff, p
+	}
+
+	// Welch-Satterthwaite degrees of freedom, rounded down for the confidence interval
+	df := math.Pow(vb+vc, 2) / (vb*vb/float64(len(base)-1) + vc*vc/float64(len(current)-1))
+
+	margin := tQuantile975(int(math.Max(1, math.Floor(df)))) * se
+	t := diff / se
+	p = regularizedIncompleteBeta(df/2, 0.5, df/(df+t*t))
+
+	return diff, diff - margin, diff + margin, p
+}
+
+// Mann-Whitney U test of a shift between the distributions of base and current, returning the two-sided p-value
+// from the normal approximation, with the correction for ties and for continuity
+func mannWhitney(base, current []float64) float64 {
+	n1, n2 := float64(len(base)), float64(len(current))
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type sample struct {
+		value float64
+		base  bool
+	}
+	var samples []sample
+	for _, m := range base {
+		samples = append(samples, sample{m, true})
+	}
+	for _, m := range current {
+		samples = append(samples, sample{m, false})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].value < samples[j].value })
+
+	// Tied values get the average of their ranks
+	var rankSum, ties float64
+	for i := 0; i < len(samples); {
+		j := i
+		for j < len(samples) && samples[j].value == samples[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if samples[k].base {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n := n1 + n2
+	u := rankSum - n1*(n1+1)/2
+	sigma := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+
+	z := math.Max(0, math.Abs(u-n1*n2/2)-0.5) / sigma
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// Regularized incomplete beta function I_x(a, b), evaluated by its continued fraction. The two-sided p-value of
+// a Student t statistic t with df degrees of freedom is I_{df/(df+t^2)}(df/2, 1/2).
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	// The continued fraction converges quickly below (a+1)/(a+b+2), and the symmetry I_x(a, b) = 1 - I_1-x(b, a)
+	// is used above
+	if x > (a+1)/(a+b+2) {
+		return 1 - regularizedIncompleteBeta(b, a, 1-x)
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab-la-lb+a*math.Log(x)+b*math.Log(1-x)) / a
+
+	// Modified Lentz's method
+	const tiny, epsilon = 1e-30, 1e-12
+	f, c, d := 1.0, 1.0, 0.0
+	for i := 0; i <= 200; i++ {
+		m := float64(i / 2)
+		var numerator float64
+		switch {
+		case i == 0:
+			numerator = 1
+		case i%2 == 0:
+			numerator = m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		default:
+			numerator = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		}
+
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		d = 1 / d
+
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+
+		f *= c * d
+		if math.Abs(1-c*d) < epsilon {
+			return front * (f - 1)
+		}
+	}
+	return front * (f - 1)
+}
+
+// Prints the comparisons, flagging the regressions beyond threshold at significance level alpha
+func PrintComparisons(comparisons []Comparison, threshold, alpha float64) {
+	//header
+	fmt.Printf("%-47s | %-24s | %-24s | ", "TestName", "Metric", "Setup")
+	fmt.Printf("%-11s | %-12s | %-12s | %-9s | %-21s | %-9s | %-9s |", "N", "MeanBase", "MeanNew", "Change", "95% CI", "WelchP", "MannWhitP")
+
+	for _, c := range comparisons {
+		//content
+		fmt.Println()
+		fmt.Printf("%23s %23s | %-24s | %-24s |", c.KEXName, c.AuthName, c.Metric, setupString(c.Setup))
+
+		fmt.Printf(" %-11s | %-12f | %-12f |", fmt.Sprintf("%d/%d", c.NBase, c.NNew), c.MeanBase, c.MeanNew)
+		fmt.Printf(" %-9s | %-21s |", percent(c.Change), fmt.Sprintf("[%s, %s]", percent(c.ChangeLow), percent(c.ChangeHigh)))
+		fmt.Printf(" %-9.4f | %-9.4f |", c.WelchP, c.MannWhitneyP)
+		if c.Regression(threshold, alpha) {
+			fmt.Print(" REGRESSION")
+		}
+	}
+	fmt.Println()
+}
+
+func percent(change float64) string {
+	return fmt.Sprintf("%+.2f%%", change*100)
+}
+
+// Kind of the handshakes, chain, key share and link of a setup, e.g. "resumed, P256_Dilithium2>default, 4g"
+func setupString(s Setup) string {
+	setup := []string{s.Handshake}
+	if s.Chain != "" {
+		setup = append(setup, s.Chain)
+	}
+	if s.KeyShare != "" {
+		setup = append(setup, "key share "+s.KeyShare)
+	}
+	if s.Link != "none" {
+		setup = append(setup, s.Link)
+	}
+	return strings.Join(setup, ", ")
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegularizedIncompleteBeta(t *testing.T) {
+	tests := []struct {
+		a, b, x float64
+		want    float64
+	}{
+		{2, 3, 0.4, 0.5248},
+		{1, 4, 0.3, 1 - math.Pow(0.7, 4)},
+		{3, 1, 0.5, 0.125},
+		{0.5, 0.5, 0.25, 1.0 / 3},
+		{2, 3, 0, 0},
+		{2, 3, 1, 1},
+	}
+
+	for _, tt := range tests {
+		if got := regularizedIncompleteBeta(tt.a, tt.b, tt.x); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("I_%g(%g, %g) = %f, want %f", tt.x, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestTQuantile975(t *testing.T) {
+	tests := []struct {
+		df   int
+		want float64
+	}{
+		{1, 12.706},
+		{5, 2.571},
+		{30, 2.042},
+		{31, 2.0395},
+		{100, 1.9840},
+	}
+
+	for _, tt := range tests {
+		if got := tQuantile975(tt.df); math.Abs(got-tt.want) > 5e-4 {
+			t.Errorf("tQuantile975(%d) = %f, want %f", tt.df, got, tt.want)
+		}
+	}
+}
+
+// The p-values of the t distribution are checked against a numerical integration of its density, and the ones
+// of the Mann-Whitney U test against a separate computation of the normal approximation
+var twoSampleTests = []struct {
+	name          string
+	base, current []float64
+
+	diff, low, high float64
+	welchP          float64
+	mannWhitneyP    float64
+}{
+	{
+		name:    "shift",
+		base:    []float64{10.1, 10.4, 9.8, 10.2, 10.0, 10.3},
+		current: []float64{10.6, 10.9, 10.5, 11.0, 10.7, 10.4, 10.8},
+		diff:    0.566667, low: 0.298894, high: 0.834439,
+		welchP:       0.000685,
+		mannWhitneyP: 0.004222,
+	},
+	{
+		name:    "ties",
+		base:    []float64{1, 2, 2, 3, 3, 3, 4},
+		current: []float64{2, 3, 3, 4, 4, 5, 5, 6},
+		diff:    1.428571, low: 0.138831, high: 2.718311,
+		welchP:       0.031646,
+		mannWhitneyP: 0.049884,
+	},
+	{
+		name:    "same mean",
+		base:    []float64{5.0, 5.2, 4.9, 5.1},
+		current: []float64{5.05, 5.15, 4.95, 5.0, 5.1},
+		diff:    0, low: -0.204308, high: 0.204308,
+		welchP:       1,
+		mannWhitneyP: 1,
+	},
+}
+
+func TestWelch(t *testing.T) {
+	for _, tt := range twoSampleTests {
+		diff, low, high, p := welch(tt.base, tt.current)
+		if math.Abs(diff-tt.diff) > 1e-5 || math.Abs(low-tt.low) > 1e-5 || math.Abs(high-tt.high) > 1e-5 {
+			t.Errorf("%s: difference %f [%f, %f], want %f [%f, %f]", tt.name, diff, low, high, tt.diff, tt.low, tt.high)
+		}
+		if math.Abs(p-tt.welchP) > 1e-5 {
+			t.Errorf("%s: Welch p-value %f, want %f", tt.name, p, tt.welchP)
+		}
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	for _, tt := range twoSampleTests {
+		if p := mannWhitney(tt.base, tt.current); math.Abs(p-tt.mannWhitneyP) > 1e-5 {
+			t.Errorf("%s: Mann-Whitney p-value %f, want %f", tt.name, p, tt.mannWhitneyP)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+)
+
+// Consecutive messages of a handshake sent by the same side, before the other side answers
+type Flight struct {
+	// Whether the flight was sent by the client, or by the server
+	Client bool
+
+	// Bytes of the TLS records of the flight
+	Bytes int
+
+	// Time of the first write or read of the flight, in milliseconds since the connection was established
+	Start float64
+}
+
+// Flights of a handshake, as seen by the client
+type HandshakeFlights struct {
+	Flights []Flight
+
+	// Server flights received by the client before it sends its first application byte
+	RoundTrips int
+
+	// Bytes of the first server flight
+	ServerFirstFlight int
+
+	// Time the client received the server response to its first application message, in milliseconds since the
+	// connection was established
+	TimeToFirstByte float64
+
+	// Whether the server answered the first ClientHello with a HelloRetryRequest, asking for another key share
+	HelloRetryRequest bool
+
+	// Client worker that performed the handshake, and the start and the end of its connection, in milliseconds
+	// since the handshakes of the pair of algorithms started
+	Worker     int
+	Start, End float64
+
+	// Round of the handshake, if the handshakes of the servers are interleaved
+	Round int
+}
+
+// Reports whether the first server flight does not fit in the TCP initial congestion window initCwnd, in bytes,
+// so that the server waits for an acknowledgment of the client before finishing it
+func (f HandshakeFlights) ExceedsInitCwnd(initCwnd int) bool {
+	return f.ServerFirstFlight > initCwnd
+}
+
+// Duration of the connection of the handshake, in milliseconds
+func (f HandshakeFlights) Latency() float64 {
+	return f.End - f.Start
+}
+
+// Latencies of the handshakes, in milliseconds
+func Latencies(flights []HandshakeFlights) []float64 {
+	latencies := make([]float64, len(flights))
+	for i, f := range flights {
+		latencies[i] = f.Latency()
+	}
+	return latencies
+}
+
+// Handshakes per second, from the start of the first connection to the end of the last one
+func Throughput(flights []HandshakeFlights) float64 {
+	if len(flights) == 0 {
+		return 0
+	}
+
+	first, last := flights[0].Start, flights[0].End
+	for _, f := range flights {
+		first = math.Min(first, f.Start)
+		last = math.Max(last, f.End)
+	}
+	if last <= first {
+		return 0
+	}
+	return float64(len(flights)) / (last - first) * 1000
+}
+
+// Flight sizes, e.g. "C:517;S:4213;C:74", where C are the client flights and S the server ones
+func (f HandshakeFlights) BytesString() string {
+	var flights []string
+	for _, flight := range f.Flights {
+		sender := "S"
+		if flight.Client {
+			sender = "C"
+		}
+		flights = append(flights, fmt.Sprintf("%s:%d", sender, flight.Bytes))
+	}
+	return strings.Join(flights, ";")
+}
+
+// Flight start times in milliseconds, separated by semicolons
+func (f HandshakeFlights) StartsString() string {
+	var starts []string
+	for _, flight := range f.Flights {
+		starts = append(starts, fmt.Sprintf("%f", flight.Start))
+	}
+	return strings.Join(starts, ";")
+}
+
+func InitFlightsCSV(files Files) {
+	csvFile, err := os.Create(files.ClientFlights)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	csvwriter := csv.NewWriter(csvFile)
+
+	header := []string{"kex", "auth", "flights", "flightBytes", "flightStarts", "roundTrips", "serverFirstFlight", "initCwnd", "exceedsInitCwnd", "timeToFirstByte", "keyShare", "helloRetryRequest", "worker", "handshakeStart", "handshakeEnd", "round", "seed", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "link"}
+
+	csvwriter.Write(header)
+	csvwriter.Flush()
+	csvFile.Close()
+}
+
+// Saves the flights of the handshakes of a pair of algorithms. initCwnd is the TCP initial congestion window
+// in bytes.
+func SaveFlightsCSV(files Files, kexAlgo string, authAlgo string, levels Levels, flights []HandshakeFlights, initCwnd int) {
+	kexLevel, authLevel, caLevel := levels.strings()
+
+	keyShare := files.initialKeyShare(kexAlgo)
+
+	csvFile, err := os.OpenFile(files.ClientFlights, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		log.Fatalf("failed opening file: %s", err)
+	}
+
+	csvwriter := csv.NewWriter(csvFile)
+
+	for _, f := range flights {
+		round := ""
+		if files.Seed != "" {
+			round = fmt.Sprintf("%d", f.Round)
+		}
+
+		arrayStr := []string{
+			kexAlgo, authAlgo,
+			fmt.Sprintf("%d", len(f.Flights)),
+			f.BytesString(),
+			f.StartsString(),
+			fmt.Sprintf("%d", f.RoundTrips),
+			fmt.Sprintf("%d", f.ServerFirstFlight),
+			fmt.Sprintf("%d", initCwnd),
+			fmt.Sprintf("%t", f.ExceedsInitCwnd(initCwnd)),
+			fmt.Sprintf("%f", f.TimeToFirstByte),
+			keyShare,
+			fmt.Sprintf("%t", f.HelloRetryRequest),
+			fmt.Sprintf("%d", f.Worker),
+			fmt.Sprintf("%f", f.Start),
+			fmt.Sprintf("%f", f.End),
+			round, files.Seed,
+			kexLevel, authLevel, caLevel, files.Handshake, files.Chain, files.Link,
+		}
+
+		if err := csvwriter.Write(arrayStr); err != nil {
+			log.Fatalln("error writing record to file", err)
+		}
+	}
+	csvwriter.Flush()
+	csvFile.Close()
+}
@@ -1,4 +1,4 @@
-package main
+package stats
 
 import (
 	"encoding/csv"
@@ -9,22 +9,22 @@ import (
 )
 
 type KEMTLSClientResultsInfo struct {
-	kexName                 string
-	authName                string
-	avgTotalTime            float64
-	avgSendAppDataTime 			float64
-	avgWriteKEMCiphertext   float64
-	avgProcessServerHello   float64
-	avgWriteClientHello     float64
-	stdevTotalTime          float64
-	stdevSendAppDataTime 			float64
-	stdevWriteKEMCiphertext float64
-	stdevProcessServerHello float64
-	stdevWriteClientHello   float64
+	KEXName                 string
+	AuthName                string
+	AvgTotalTime            float64
+	AvgSendAppDataTime 			float64
+	AvgWriteKEMCiphertext   float64
+	AvgProcessServerHello   float64
+	AvgWriteClientHello     float64
+	StdevTotalTime          float64
+	StdevSendAppDataTime 			float64
+	StdevWriteKEMCiphertext float64
+	StdevProcessServerHello float64
+	StdevWriteClientHello   float64
 }
 
 //Stats: Avg, Stdev.
-func kemtlsComputeStats(timingsFullProtocol []float64, timingsSendAppData []float64, timingsProcessServerHello []float64, timingsWriteClientHello []float64, timingsWriteKEMCiphertext []float64, hs int) (r KEMTLSClientResultsInfo) {
+func KEMTLSComputeStats(timingsFullProtocol []float64, timingsSendAppData []float64, timingsProcessServerHello []float64, timingsWriteClientHello []float64, timingsWriteKEMCiphertext []float64, hs int) (r KEMTLSClientResultsInfo) {
 
 	//counts
 	var countTotalTime float64
@@ -43,57 +43,31 @@ func kemtlsComputeStats(timingsFullProtocol []float64, timingsSendAppData []floa
 		countWriteKEMCiphertext += timingsWriteKEMCiphertext[i]
 	}
 
-	r.avgTotalTime = (countTotalTime) / float64(hs)
-	r.avgSendAppDataTime = (countSendAppDataTime) / float64(hs)
-	r.avgProcessServerHello = (countProcessServerHello) / float64(hs)
-	r.avgWriteClientHello = (countWriteClientHello) / float64(hs)
-	r.avgWriteKEMCiphertext = (countWriteKEMCiphertext) / float64(hs)
+	r.AvgTotalTime = (countTotalTime) / float64(hs)
+	r.AvgSendAppDataTime = (countSendAppDataTime) / float64(hs)
+	r.AvgProcessServerHello = (countProcessServerHello) / float64(hs)
+	r.AvgWriteClientHello = (countWriteClientHello) / float64(hs)
+	r.AvgWriteKEMCiphertext = (countWriteKEMCiphertext) / float64(hs)
 
 	//stdev
 	for i := 0; i < hs; i++ {
-		r.stdevTotalTime += math.Pow(float64(timingsFullProtocol[i])-r.avgTotalTime, 2)
-		r.stdevSendAppDataTime += math.Pow(float64(timingsSendAppData[i])-r.avgSendAppDataTime, 2)
-		r.stdevProcessServerHello += math.Pow(float64(timingsProcessServerHello[i])-r.avgProcessServerHello, 2)
-		r.stdevWriteClientHello += math.Pow(float64(timingsWriteClientHello[i])-r.avgWriteClientHello, 2)
-		r.stdevWriteKEMCiphertext += math.Pow(float64(timingsWriteKEMCiphertext[i])-r.avgWriteKEMCiphertext, 2)
+		r.StdevTotalTime += math.Pow(float64(timingsFullProtocol[i])-r.AvgTotalTime, 2)
+		r.StdevSendAppDataTime += math.Pow(float64(timingsSendAppData[i])-r.AvgSendAppDataTime, 2)
+		r.StdevProcessServerHello += math.Pow(float64(timingsProcessServerHello[i])-r.AvgProcessServerHello, 2)
+		r.StdevWriteClientHello += math.Pow(float64(timingsWriteClientHello[i])-r.AvgWriteClientHello, 2)
+		r.StdevWriteKEMCiphertext += math.Pow(float64(timingsWriteKEMCiphertext[i])-r.AvgWriteKEMCiphertext, 2)
 	}
-	r.stdevTotalTime = math.Sqrt(r.stdevTotalTime / float64(hs))
-	r.stdevSendAppDataTime = math.Sqrt(r.stdevSendAppDataTime / float64(hs))
-	r.stdevProcessServerHello = math.Sqrt(r.stdevProcessServerHello / float64(hs))
-	r.stdevWriteClientHello = math.Sqrt(r.stdevWriteClientHello / float64(hs))
-	r.stdevWriteKEMCiphertext = math.Sqrt(r.stdevWriteKEMCiphertext / float64(hs))
+	r.StdevTotalTime = math.Sqrt(r.StdevTotalTime / float64(hs))
+	r.StdevSendAppDataTime = math.Sqrt(r.StdevSendAppDataTime / float64(hs))
+	r.StdevProcessServerHello = math.Sqrt(r.StdevProcessServerHello / float64(hs))
+	r.StdevWriteClientHello = math.Sqrt(r.StdevWriteClientHello / float64(hs))
+	r.StdevWriteKEMCiphertext = math.Sqrt(r.StdevWriteKEMCiphertext / float64(hs))
 
 	return r
 }
 
-//Stats: Avg, Stdev.
-func computeStats(measurements []float64) (avg float64, stdev float64) {
-
-	//counts
-	var countTotalTime float64	
-
-	numOfMeasurements := len(measurements) 
-
-	//Average
-	countTotalTime = 0;
-	for i := 0; i < numOfMeasurements; i++ {
-		countTotalTime += measurements[i]		
-	}
-
-	avg = (countTotalTime) / float64(numOfMeasurements)
-
-	//stdev
-	for i := 0; i < numOfMeasurements; i++ {
-		stdev += math.Pow(float64(measurements[i]) - avg, 2)
-	}
-
-	stdev = math.Sqrt(stdev / float64(numOfMeasurements))
-
-	return avg, stdev
-}
-
 //print results
-func kemtlsPrintStatistics(results []KEMTLSClientResultsInfo) {
+func KEMTLSPrintStatistics(results []KEMTLSClientResultsInfo) {
 	//header
 	fmt.Printf("%-23s | ", "TestName")
 	fmt.Printf("%-20s | ", "AvgClientTotalTime")
@@ -110,115 +84,88 @@ func kemtlsPrintStatistics(results []KEMTLSClientResultsInfo) {
 	for _, r := range results {
 		//content
 		fmt.Println()
-		fmt.Printf("%-23s |", r.kexName)
-
-		fmt.Printf(" %-20f |", r.avgTotalTime)
-		fmt.Printf(" %-20f |", r.stdevTotalTime)
-		fmt.Printf(" %-20f |", r.avgSendAppDataTime)
-		fmt.Printf(" %-20f |", r.stdevSendAppDataTime)
-		fmt.Printf(" %-20f |", r.avgWriteClientHello)
-		fmt.Printf(" %-20f |", r.stdevWriteClientHello)
-		fmt.Printf(" %-20f |", r.avgProcessServerHello)
-		fmt.Printf(" %-20f |", r.stdevProcessServerHello)
-		fmt.Printf(" %-20f |", r.avgWriteKEMCiphertext)
-		fmt.Printf(" %-20f ", r.stdevWriteKEMCiphertext)
-	}
-}
-
-func getClientResultsFileName() string {
-	if *cachedCert {
-		if *classicMcEliece {
-			return "csv/kemtls-pdk-classic-mceliece-client.csv"
-		} else {
-			return "csv/kemtls-pdk-client.csv"
-		}		
-	} else {
-		return "csv/kemtls-client.csv"
-	}
-}
-
-func getServerResultsFileName() string {
-	if *cachedCert {
-		if *classicMcEliece {
-			return "csv/kemtls-pdk-classic-mceliece-server.csv"
-		} else {
-			return "csv/kemtls-pdk-server.csv"
-		}		
-	} else {
-		return "csv/kemtls-server.csv"
+		fmt.Printf("%-23s |", r.KEXName)
+
+		fmt.Printf(" %-20f |", r.AvgTotalTime)
+		fmt.Printf(" %-20f |", r.StdevTotalTime)
+		fmt.Printf(" %-20f |", r.AvgSendAppDataTime)
+		fmt.Printf(" %-20f |", r.StdevSendAppDataTime)
+		fmt.Printf(" %-20f |", r.AvgWriteClientHello)
+		fmt.Printf(" %-20f |", r.StdevWriteClientHello)
+		fmt.Printf(" %-20f |", r.AvgProcessServerHello)
+		fmt.Printf(" %-20f |", r.StdevProcessServerHello)
+		fmt.Printf(" %-20f |", r.AvgWriteKEMCiphertext)
+		fmt.Printf(" %-20f ", r.StdevWriteKEMCiphertext)
 	}
 }
 
-func getClientSizesResultsFileName() string {
-	if *cachedCert {
-		if *classicMcEliece {
-			return "csv/kemtls-pdk-classic-mceliece-client-sizes.csv"
+// Results files of the KEMTLS handshakes
+func KEMTLSFiles(cachedCert, classicMcEliece bool) Files {
+	prefix := "csv/kemtls"
+	if cachedCert {
+		if classicMcEliece {
+			prefix = "csv/kemtls-pdk-classic-mceliece"
 		} else {
-			return "csv/kemtls-pdk-client-sizes.csv"
-		}		
-	} else {
-		return "csv/kemtls-client-sizes.csv"
+			prefix = "csv/kemtls-pdk"
+		}
 	}
-}
 
-func getServerSizesResultsFileName() string {
-	if *cachedCert {
-		if *classicMcEliece {
-			return "csv/kemtls-pdk-classic-mceliece-server-sizes.csv"
-		} else {
-			return "csv/kemtls-pdk-server-sizes.csv"
-		}		
-	} else {
-		return "csv/kemtls-server-sizes.csv"
+	return Files{
+		Client:        prefix + "-client.csv",
+		Server:        prefix + "-server.csv",
+		ClientSizes:   prefix + "-client-sizes.csv",
+		ServerSizes:   prefix + "-server-sizes.csv",
+		ClientFlights: prefix + "-client-flights.csv",
+		ClientSummary: prefix + "-client-summary.csv",
 	}
 }
 
-func kemtlsInitCSV() {
-	csvFile, err := os.Create(getClientResultsFileName())
+func KEMTLSInitCSV(files Files) {
+	csvFile, err := os.Create(files.Client)
 	if err != nil {
 		log.Fatalf("failed creating file: %s", err)
 	}
 	csvwriter := csv.NewWriter(csvFile)
 
-	header := []string{"kex", "auth", "timingFullProtocol", "timingSendAppData", "timingProcessServerHello", "timingWriteClientHello", "timingWriteKEMCiphertext"}
+	header := []string{"kex", "auth", "timingFullProtocol", "timingSendAppData", "timingProcessServerHello", "timingWriteClientHello", "timingWriteKEMCiphertext", "cpuUser", "cpuSystem", "allocs", "allocBytes", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
 
 	csvwriter.Write(header)
 	csvwriter.Flush()
 	csvFile.Close()
 
-	csvFile, err = os.Create(getClientSizesResultsFileName())
+	csvFile, err = os.Create(files.ClientSizes)
 	if err != nil {
 		log.Fatalf("failed creating file: %s", err)
 	}
 	csvwriter = csv.NewWriter(csvFile)
 
-	header = []string{"kex", "auth", "ClientHello", "ClientKEMCiphertext", "Certificate", "Finished", "Total"}
+	header = []string{"kex", "auth", "ClientHello", "ClientKEMCiphertext", "Certificate", "Finished", "Total", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
 
 	csvwriter.Write(header)
 	csvwriter.Flush()
 	csvFile.Close()
 }
 
-func kemtlsInitCSVServer() {
-	csvFile, err := os.Create(getServerResultsFileName())
+func KEMTLSInitCSVServer(files Files) {
+	csvFile, err := os.Create(files.Server)
 	if err != nil {
 		log.Fatalf("failed creating file: %s", err)
 	}
 	csvwriter := csv.NewWriter(csvFile)
 
-	header := []string{"kex", "auth", "timingFullProtocol", "timingWriteServerHello", "timingReadKEMCiphertext"}
+	header := []string{"kex", "auth", "timingFullProtocol", "timingWriteServerHello", "timingReadKEMCiphertext", "cpuUser", "cpuSystem", "allocs", "allocBytes", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
 
 	csvwriter.Write(header)
 	csvwriter.Flush()
 	csvFile.Close()
 
-	csvFile, err = os.Create(getServerSizesResultsFileName())
+	csvFile, err = os.Create(files.ServerSizes)
 	if err != nil {
 		log.Fatalf("failed creating file: %s", err)
 	}
 	csvwriter = csv.NewWriter(csvFile)
 
-	header = []string{"kex", "auth", "ServerHello", "EncryptedExtensions", "Certificate", "CertificateRequest", "ServerKEMCiphertext", "Finished", "Total"}
+	header = []string{"kex", "auth", "ServerHello", "EncryptedExtensions", "Certificate", "CertificateRequest", "ServerKEMCiphertext", "Finished", "Total", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
 
 	csvwriter.Write(header)
 	csvwriter.Flush()
@@ -227,8 +174,11 @@ func kemtlsInitCSVServer() {
 
 
 
-func kemtlsSaveCSV(timingsFullProtocol []float64, timingsSendAppData []float64, timingsProcessServerHello []float64, timingsWriteClientHello []float64, timingsWriteKEMCiphertext []float64, kexAlgo string, authAlgo string, hs int, sizes map[string]uint32) {
-	csvFile, err := os.OpenFile(getClientResultsFileName(), os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+func KEMTLSSaveCSV(files Files, timingsFullProtocol []float64, timingsSendAppData []float64, timingsProcessServerHello []float64, timingsWriteClientHello []float64, timingsWriteKEMCiphertext []float64, kexAlgo string, authAlgo string, levels Levels, hs int, sizes map[string]uint32, resources []Resources) {
+	kexLevel, authLevel, caLevel := levels.strings()
+	keyShare := files.initialKeyShare(kexAlgo)
+
+	csvFile, err := os.OpenFile(files.Client, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
 	if err != nil {
 		log.Fatalf("failed opening file: %s", err)
 	}
@@ -243,6 +193,8 @@ func kemtlsSaveCSV(timingsFullProtocol []float64, timingsSendAppData []float64,
 			fmt.Sprintf("%f", timingsProcessServerHello[i]),
 			fmt.Sprintf("%f", timingsWriteClientHello[i]),
 			fmt.Sprintf("%f", timingsProcessServerHello[i])}
+		arrayStr = append(arrayStr, resourcesColumns(resources, i)...)
+		arrayStr = append(arrayStr, kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link)
 
 		if err := csvwriter.Write(arrayStr); err != nil {
 			log.Fatalln("error writing record to file", err)
@@ -251,7 +203,7 @@ func kemtlsSaveCSV(timingsFullProtocol []float64, timingsSendAppData []float64,
 	}
 	csvFile.Close()
 
-	csvFile, err = os.OpenFile(getClientSizesResultsFileName(), os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	csvFile, err = os.OpenFile(files.ClientSizes, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
 	if err != nil {
 		log.Fatalf("failed opening file: %s", err)
 	}
@@ -267,6 +219,7 @@ func kemtlsSaveCSV(timingsFullProtocol []float64, timingsSendAppData []float64,
 		fmt.Sprintf("%d", sizes["Certificate"]),
 		fmt.Sprintf("%d", sizes["Finished"]),
 		fmt.Sprintf("%d", totalSizes),
+		kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link,
 	}
 
 	if err := csvwriter.Write(arrayStr); err != nil {
@@ -276,8 +229,11 @@ func kemtlsSaveCSV(timingsFullProtocol []float64, timingsSendAppData []float64,
 	csvFile.Close()
 }
 
-func kemtlsSaveCSVServer(timingsFullProtocol []float64, timingsWriteServerHello []float64, timingsReadKEMCiphertext []float64, kexAlgo string, authAlgo string, hs int, sizes map[string]uint32) {
-	csvFile, err := os.OpenFile(getServerResultsFileName(), os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+func KEMTLSSaveCSVServer(files Files, timingsFullProtocol []float64, timingsWriteServerHello []float64, timingsReadKEMCiphertext []float64, kexAlgo string, authAlgo string, levels Levels, hs int, sizes map[string]uint32, resources []Resources) {
+	kexLevel, authLevel, caLevel := levels.strings()
+	keyShare := files.initialKeyShare(kexAlgo)
+
+	csvFile, err := os.OpenFile(files.Server, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
 	if err != nil {
 		log.Fatalf("failed opening file: %s", err)
 	}
@@ -290,6 +246,8 @@ func kemtlsSaveCSVServer(timingsFullProtocol []float64, timingsWriteServerHello
 			fmt.Sprintf("%f", timingsFullProtocol[i]),
 			fmt.Sprintf("%f", timingsWriteServerHello[i]),
 			fmt.Sprintf("%f", timingsReadKEMCiphertext[i])}
+		arrayStr = append(arrayStr, resourcesColumns(resources, i)...)
+		arrayStr = append(arrayStr, kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link)
 
 		if err := csvwriter.Write(arrayStr); err != nil {
 			log.Fatalln("error writing record to file", err)
@@ -298,7 +256,7 @@ func kemtlsSaveCSVServer(timingsFullProtocol []float64, timingsWriteServerHello
 	}
 	csvFile.Close()
 
-	csvFile, err = os.OpenFile(getServerSizesResultsFileName(), os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	csvFile, err = os.OpenFile(files.ServerSizes, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
 	if err != nil {
 		log.Fatalf("failed opening file: %s", err)
 	}
@@ -316,6 +274,7 @@ func kemtlsSaveCSVServer(timingsFullProtocol []float64, timingsWriteServerHello
 		fmt.Sprintf("%d", sizes["ServerKEMCiphertext"]),
 		fmt.Sprintf("%d", sizes["Finished"]),
 		fmt.Sprintf("%d", totalSizes),
+		kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link,
 	}
 
 	if err := csvwriter.Write(arrayStr); err != nil {
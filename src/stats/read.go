@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Timings of a pair of algorithms in a setup read from a client results file. Timings holds a slice of
+// measurements for each timing column of the file, in the order of the file header.
+type Measurements struct {
+	KEXName  string
+	AuthName string
+	Timings  [][]float64
+
+	Setup
+}
+
+// Conditions of the measurements of a pair of algorithms, recorded in the last columns of the results files
+type Setup struct {
+	// Kind of the handshakes, or "full" in the files saved before the handshake column was recorded
+	Handshake string
+
+	// Certificate chain, or "" in the files saved before the chain column was recorded
+	Chain string
+
+	// Key exchange of the initial key share of the client if it is not the one of the pair, so that the handshakes
+	// begin with a HelloRetryRequest, or ""
+	KeyShare string
+
+	// Emulated link profile, or "none" in the files saved before the link column was recorded
+	Link string
+}
+
+// Reports whether the client results file header is the KEMTLS one
+func IsKEMTLSHeader(header []string) bool {
+	return len(header) >= 7 && header[0] == "kex" && header[3] == "timingSendAppData"
+}
+
+// Reads a client results file, grouping the measurements by pair of algorithms and setup in the order they
+// first appear
+func ReadClientResults(fileName string) (header []string, results []Measurements, err error) {
+	csvFile, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer csvFile.Close()
+
+	records, err := csv.NewReader(csvFile).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%s: missing header", fileName)
+	}
+
+	header = records[0]
+	if len(header) < 3 {
+		return nil, nil, fmt.Errorf("%s: not a client results file", fileName)
+	}
+
+	// Timing columns, between the algorithms and the resources, the levels or the link, in the files saved before
+	// the resources or the levels were recorded
+	timings := len(header) - 2
+	for c, column := range header[2:] {
+		if column == "cpuUser" || column == "kexLevel" || column == "link" {
+			timings = c
+			break
+		}
+	}
+	hasLink := header[len(header)-1] == "link"
+	handshakeColumn, chainColumn, keyShareColumn := -1, -1, -1
+	for c, column := range header {
+		switch column {
+		case "handshake":
+			handshakeColumn = c
+		case "chain":
+			chainColumn = c
+		case "keyShare":
+			keyShareColumn = c
+		}
+	}
+
+	index := make(map[string]int)
+
+	for line, record := range records[1:] {
+		setup := Setup{Handshake: FullHandshake, Link: "none"}
+		if handshakeColumn >= 0 {
+			setup.Handshake = record[handshakeColumn]
+		}
+		if chainColumn >= 0 {
+			setup.Chain = record[chainColumn]
+		}
+		if keyShareColumn >= 0 && record[keyShareColumn] != record[0] {
+			setup.KeyShare = record[keyShareColumn]
+		}
+		if hasLink {
+			setup.Link = record[len(record)-1]
+		}
+
+		key := strings.Join([]string{record[0], record[1], setup.Handshake, setup.Chain, setup.KeyShare, setup.Link}, ",")
+		i, ok := index[key]
+		if !ok {
+			i = len(results)
+			index[key] = i
+			results = append(results, Measurements{KEXName: record[0], AuthName: record[1], Timings: make([][]float64, timings), Setup: setup})
+		}
+
+		for c, field := range record[2 : 2+timings] {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s:%d: %v", fileName, line+2, err)
+			}
+			results[i].Timings[c] = append(results[i].Timings[c], value)
+		}
+	}
+
+	return header, results, nil
+}
+
+// Statistics of measurements read from a KEMTLS client results file
+func (m Measurements) KEMTLSStats() (KEMTLSClientResultsInfo, error) {
+	if len(m.Timings) != 5 {
+		return KEMTLSClientResultsInfo{}, errors.New("not KEMTLS client measurements")
+	}
+
+	r := KEMTLSComputeStats(m.Timings[0], m.Timings[1], m.Timings[2], m.Timings[3], m.Timings[4], len(m.Timings[0]))
+	r.KEXName = m.KEXName
+	r.AuthName = m.AuthName
+	return r, nil
+}
+
+// Statistics of measurements read from a PQTLS or classic TLS client results file
+func (m Measurements) TLSStats() (TLSClientResultsInfo, error) {
+	if len(m.Timings) != 3 {
+		return TLSClientResultsInfo{}, errors.New("not TLS client measurements")
+	}
+
+	r := TLSComputeStats(m.Timings[0], m.Timings[1], m.Timings[2], len(m.Timings[0]))
+	r.KEXName = m.KEXName
+	r.AuthName = m.AuthName
+	return r, nil
+}
+
+// Link profiles of measurements, in the order they first appear
+func Links(measurements []Measurements) []string {
+	return distinct(measurements, func(m Measurements) string { return m.Link })
+}
+
+// Certificate chains of measurements, in the order they first appear
+func Chains(measurements []Measurements) []string {
+	return distinct(measurements, func(m Measurements) string { return m.Chain })
+}
+
+// Kinds of handshakes of measurements, in the order they first appear
+func Handshakes(measurements []Measurements) []string {
+	return distinct(measurements, func(m Measurements) string { return m.Handshake })
+}
+
+// Setups of measurements, in the order they first appear
+func Setups(measurements []Measurements) []Setup {
+	var setups []Setup
+	seen := make(map[Setup]bool)
+	for _, m := range measurements {
+		if !seen[m.Setup] {
+			seen[m.Setup] = true
+			setups = append(setups, m.Setup)
+		}
+	}
+	return setups
+}
+
+func distinct(measurements []Measurements, value func(Measurements) string) []string {
+	var values []string
+	seen := make(map[string]bool)
+	for _, m := range measurements {
+		if v := value(m); !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
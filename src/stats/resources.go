@@ -0,0 +1,48 @@
+package stats
+
+import "fmt"
+
+// CPU time and Go heap allocations of the process during a handshake
+type Resources struct {
+	// User and system CPU time, in milliseconds
+	UserCPU, SystemCPU float64
+
+	// Heap objects allocated, and their bytes
+	Allocs, AllocBytes uint64
+
+	// Whether another handshake was in progress in the process meanwhile, so that the usage of the process was not
+	// the one of this handshake, which is then unknown
+	Overlapped bool
+}
+
+// Resources columns of the i-th handshake, which are empty if its resources were not measured, or overlapped the
+// ones of another handshake
+func resourcesColumns(resources []Resources, i int) []string {
+	if i >= len(resources) || resources[i].Overlapped {
+		return []string{"", "", "", ""}
+	}
+	r := resources[i]
+	return []string{fmt.Sprintf("%f", r.UserCPU), fmt.Sprintf("%f", r.SystemCPU), fmt.Sprintf("%d", r.Allocs), fmt.Sprintf("%d", r.AllocBytes)}
+}
+
+// Average CPU time and heap allocations of the handshakes that did not overlap another one, and their number
+func AverageResources(resources []Resources) (avg Resources, n int) {
+	for _, r := range resources {
+		if r.Overlapped {
+			continue
+		}
+		avg.UserCPU += r.UserCPU
+		avg.SystemCPU += r.SystemCPU
+		avg.Allocs += r.Allocs
+		avg.AllocBytes += r.AllocBytes
+		n++
+	}
+	if n == 0 {
+		return avg, 0
+	}
+	avg.UserCPU /= float64(n)
+	avg.SystemCPU /= float64(n)
+	avg.Allocs /= uint64(n)
+	avg.AllocBytes /= uint64(n)
+	return avg, n
+}
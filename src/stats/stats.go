@@ -0,0 +1,105 @@
+// Package stats computes the statistics of the handshake timings, and saves them to the CSV results files.
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Results files of the handshake timings and message sizes of the client and the server
+type Files struct {
+	Client      string
+	Server      string
+	ClientSizes string
+	ServerSizes string
+
+	// Flights of the client handshakes
+	ClientFlights string
+
+	// Key exchange of the initial key share of the client, recorded before the link column of each file, if it is
+	// not the one of the server
+	KeyShare string
+
+	// Seed of the random order of the interleaved client handshakes, recorded in the flights file, if they are
+	// interleaved
+	Seed string
+
+	// Summaries of the client timings of each pair of algorithms
+	ClientSummary string
+
+	// Kind of the handshakes, full or resumed, recorded before the chain column of each file
+	Handshake string
+
+	// Certificate chain of the handshakes, from the Root CA down, recorded before the link column of each file
+	Chain string
+
+	// Emulated link profile of the handshakes, recorded in the last column of each file
+	Link string
+}
+
+// Kinds of handshakes
+const (
+	FullHandshake    = "full"
+	ResumedHandshake = "resumed"
+)
+
+// NIST levels of the key exchange, the authentication and the Intermediate CA of a pair of algorithms, recorded
+// before the handshake column of each file
+type Levels struct {
+	KEX, Auth, CA int
+}
+
+func (l Levels) strings() (kexLevel, authLevel, caLevel string) {
+	return fmt.Sprintf("%d", l.KEX), fmt.Sprintf("%d", l.Auth), fmt.Sprintf("%d", l.CA)
+}
+
+// Key exchange of the initial key share of the client in the handshakes of kexAlgo
+func (f Files) initialKeyShare(kexAlgo string) string {
+	if f.KeyShare == "" {
+		return kexAlgo
+	}
+	return f.KeyShare
+}
+
+// Percentile p, from 0 to 100, of the measurements, interpolated linearly between the closest ranks. It is 0
+// if there are no measurements.
+func Percentile(measurements []float64, p float64) float64 {
+	if len(measurements) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), measurements...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+//Stats: Avg, Stdev.
+func ComputeStats(measurements []float64) (avg float64, stdev float64) {
+
+	//counts
+	var countTotalTime float64	
+
+	numOfMeasurements := len(measurements) 
+
+	//Average
+	countTotalTime = 0;
+	for i := 0; i < numOfMeasurements; i++ {
+		countTotalTime += measurements[i]		
+	}
+
+	avg = (countTotalTime) / float64(numOfMeasurements)
+
+	//stdev
+	for i := 0; i < numOfMeasurements; i++ {
+		stdev += math.Pow(float64(measurements[i]) - avg, 2)
+	}
+
+	stdev = math.Sqrt(stdev / float64(numOfMeasurements))
+
+	return avg, stdev
+}
@@ -0,0 +1,248 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Resamples of the bootstrap confidence intervals
+const bootstrapResamples = 2000
+
+// Distribution of the measurements of a timing, in milliseconds
+type Summary struct {
+	// Measurements summarized, and the ones rejected as outliers before
+	N        int
+	Outliers int
+
+	// Mean and sample standard deviation
+	Mean  float64
+	Stdev float64
+
+	Min, Median, P90, P95, P99, Max float64
+
+	// Interquartile range, between the 25th and the 75th percentiles
+	IQR float64
+
+	// 95% confidence intervals of the mean, from the Student t distribution and by percentile bootstrap
+	CILow, CIHigh     float64
+	BootLow, BootHigh float64
+}
+
+// Summarizes the measurements, rejecting the outliers first if rejectOutliers is set
+func Summarize(measurements []float64, rejectOutliers bool) Summary {
+	var s Summary
+
+	if rejectOutliers {
+		measurements, s.Outliers = RejectOutliers(measurements)
+	}
+
+	s.N = len(measurements)
+	if s.N == 0 {
+		return s
+	}
+
+	for _, m := range measurements {
+		s.Mean += m
+	}
+	s.Mean /= float64(s.N)
+
+	if s.N > 1 {
+		for _, m := range measurements {
+			s.Stdev += math.Pow(m-s.Mean, 2)
+		}
+		s.Stdev = math.Sqrt(s.Stdev / float64(s.N-1))
+	}
+
+	s.Min = Percentile(measurements, 0)
+	s.Median = Percentile(measurements, 50)
+	s.P90 = Percentile(measurements, 90)
+	s.P95 = Percentile(measurements, 95)
+	s.P99 = Percentile(measurements, 99)
+	s.Max = Percentile(measurements, 100)
+	s.IQR = Percentile(measurements, 75) - Percentile(measurements, 25)
+
+	s.CILow, s.CIHigh = s.Mean, s.Mean
+	if s.N > 1 {
+		margin := tQuantile975(s.N-1) * s.Stdev / math.Sqrt(float64(s.N))
+		s.CILow, s.CIHigh = s.Mean-margin, s.Mean+margin
+	}
+	s.BootLow, s.BootHigh = bootstrapMeanCI(measurements)
+
+	return s
+}
+
+// Half-width of the 95% confidence interval of the mean of the measurements, from the Student t distribution,
+// relative to the mean, e.g. 0.02 for a mean known within 2%. It is infinite for fewer than 2 measurements or a
+// zero mean.
+func RelativeHalfWidth(measurements []float64) float64 {
+	n := len(measurements)
+	if n < 2 {
+		return math.Inf(1)
+	}
+
+	var mean, variance float64
+	for _, m := range measurements {
+		mean += m
+	}
+	mean /= float64(n)
+	if mean == 0 {
+		return math.Inf(1)
+	}
+	for _, m := range measurements {
+		variance += math.Pow(m-mean, 2)
+	}
+	variance /= float64(n - 1)
+
+	return tQuantile975(n-1) * math.Sqrt(variance/float64(n)) / math.Abs(mean)
+}
+
+// Removes the measurements outside of the Tukey fences, 1.5 interquartile ranges below the 25th percentile or
+// above the 75th one, returning the kept measurements and the number of rejected ones
+func RejectOutliers(measurements []float64) (kept []float64, rejected int) {
+	q1, q3 := Percentile(measurements, 25), Percentile(measurements, 75)
+	low, high := q1-1.5*(q3-q1), q3+1.5*(q3-q1)
+
+	for _, m := range measurements {
+		if m < low || m > high {
+			rejected++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept, rejected
+}
+
+// 97.5th percentile of the Student t distribution with df degrees of freedom, which bounds the 95% confidence
+// intervals
+func tQuantile975(df int) float64 {
+	table := []float64{
+		12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+		2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+		2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+	}
+	if df <= len(table) {
+		return table[df-1]
+	}
+
+	// Cornish-Fisher expansion around the normal quantile, accurate to 3 decimals above 30 degrees of freedom
+	z := 1.959964
+	n := float64(df)
+	return z + (math.Pow(z, 3)+z)/(4*n) + (5*math.Pow(z, 5)+16*math.Pow(z, 3)+3*z)/(96*n*n) +
+		(3*math.Pow(z, 7)+19*math.Pow(z, 5)+17*math.Pow(z, 3)-15*z)/(384*n*n*n)
+}
+
+// 95% percentile bootstrap confidence interval of the mean. The resamples are seeded by the number of
+// measurements, so that the intervals of the same measurements are reproducible.
+func bootstrapMeanCI(measurements []float64) (low, high float64) {
+	n := len(measurements)
+	r := rand.New(rand.NewSource(int64(n)))
+
+	means := make([]float64, bootstrapResamples)
+	for i := range means {
+		var sum float64
+		for j := 0; j < n; j++ {
+			sum += measurements[r.Intn(n)]
+		}
+		means[i] = sum / float64(n)
+	}
+	sort.Float64s(means)
+
+	return Percentile(means, 2.5), Percentile(means, 97.5)
+}
+
+// Measurements of a timing of the handshakes of a pair of algorithms, named as its column of the results files
+type Series struct {
+	Metric string
+	Values []float64
+}
+
+// Name of the metric of a timing column of the results files. The first timing column of the TLS client files
+// holds the full protocol time.
+func MetricName(column string) string {
+	return strings.Split(column, "/")[0]
+}
+
+func InitSummaryCSV(files Files) {
+	csvFile, err := os.Create(files.ClientSummary)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	csvwriter := csv.NewWriter(csvFile)
+
+	header := []string{"kex", "auth", "metric", "samples", "n", "outliers", "mean", "stdev", "ciLow", "ciHigh", "bootstrapLow", "bootstrapHigh", "min", "median", "p90", "p95", "p99", "max", "iqr", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
+
+	csvwriter.Write(header)
+	csvwriter.Flush()
+	csvFile.Close()
+}
+
+// Saves the summary of each timing of the handshakes of a pair of algorithms, rejecting the outliers first if
+// rejectOutliers is set
+func SaveSummaryCSV(files Files, kexAlgo string, authAlgo string, levels Levels, series []Series, rejectOutliers bool) {
+	kexLevel, authLevel, caLevel := levels.strings()
+	keyShare := files.initialKeyShare(kexAlgo)
+
+	csvFile, err := os.OpenFile(files.ClientSummary, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		log.Fatalf("failed opening file: %s", err)
+	}
+
+	csvwriter := csv.NewWriter(csvFile)
+
+	for _, serie := range series {
+		s := Summarize(serie.Values, rejectOutliers)
+
+		arrayStr := []string{kexAlgo, authAlgo, serie.Metric, fmt.Sprintf("%d", len(serie.Values)), fmt.Sprintf("%d", s.N), fmt.Sprintf("%d", s.Outliers)}
+		for _, value := range []float64{s.Mean, s.Stdev, s.CILow, s.CIHigh, s.BootLow, s.BootHigh, s.Min, s.Median, s.P90, s.P95, s.P99, s.Max, s.IQR} {
+			arrayStr = append(arrayStr, fmt.Sprintf("%f", value))
+		}
+		arrayStr = append(arrayStr, kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link)
+
+		if err := csvwriter.Write(arrayStr); err != nil {
+			log.Fatalln("error writing record to file", err)
+		}
+	}
+	csvwriter.Flush()
+	csvFile.Close()
+}
+
+// Summary of a timing of the handshakes of a pair of algorithms
+type CombinationSummary struct {
+	KEXName  string
+	AuthName string
+	Summary
+}
+
+// Prints the summaries of a timing of the handshakes of each pair of algorithms
+func PrintSummaries(metric string, summaries []CombinationSummary) {
+	fmt.Printf("Distribution of %s (ms):\n\n", metric)
+
+	//header
+	fmt.Printf("%-47s | ", "TestName")
+	fmt.Printf("%-6s | %-8s | ", "N", "Outliers")
+	fmt.Printf("%-12s | %-27s | %-27s | ", "Mean", "95% CI (t)", "95% CI (bootstrap)")
+	for _, column := range []string{"Min", "Median", "P90", "P95", "P99", "Max"} {
+		fmt.Printf("%-12s | ", column)
+	}
+	fmt.Printf("%-12s ", "IQR")
+
+	for _, s := range summaries {
+		//content
+		fmt.Println()
+		fmt.Printf("%23s %23s |", s.KEXName, s.AuthName)
+
+		fmt.Printf(" %-6d | %-8d |", s.N, s.Outliers)
+		fmt.Printf(" %-12f | %-27s | %-27s |", s.Mean, fmt.Sprintf("[%f, %f]", s.CILow, s.CIHigh), fmt.Sprintf("[%f, %f]", s.BootLow, s.BootHigh))
+		for _, value := range []float64{s.Min, s.Median, s.P90, s.P95, s.P99, s.Max} {
+			fmt.Printf(" %-12f |", value)
+		}
+		fmt.Printf(" %-12f ", s.IQR)
+	}
+	fmt.Println()
+}
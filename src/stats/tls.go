@@ -0,0 +1,263 @@
+package stats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+)
+
+type TLSClientResultsInfo struct {
+	KEXName                 string
+	AuthName                string
+	AvgTotalTime            float64
+	AvgProcessServerHello   float64
+	AvgWriteClientHello     float64
+	StdevTotalTime          float64
+	StdevProcessServerHello float64
+	StdevWriteClientHello   float64
+}
+
+type TLSServerResultsInfo struct {
+	KEXName              string
+	AuthName             string
+	AvgTotalTime         float64
+	AvgWriteCertVerify   float64
+	StdevTotalTime       float64
+	StdevWriteCertVerify float64
+}
+
+//Stats: Avg, Stdev.
+func TLSComputeStats(timingsFullProtocol []float64, timingsProcessServerHello []float64, timingsWriteClientHello []float64, hs int) (r TLSClientResultsInfo) {
+
+	//counts
+	var countTotalTime float64
+	var countProcessServerHello float64
+	var countWriteClientHello float64
+
+	//Average
+	countTotalTime, countProcessServerHello, countWriteClientHello = 0, 0, 0
+	for i := 0; i < hs; i++ {
+		countTotalTime += timingsFullProtocol[i]
+		countProcessServerHello += timingsProcessServerHello[i]
+		countWriteClientHello += timingsWriteClientHello[i]
+	}
+
+	r.AvgTotalTime = (countTotalTime) / float64(hs)
+	r.AvgProcessServerHello = (countProcessServerHello) / float64(hs)
+	r.AvgWriteClientHello = (countWriteClientHello) / float64(hs)
+
+	//stdev
+	for i := 0; i < hs; i++ {
+		r.StdevTotalTime += math.Pow(float64(timingsFullProtocol[i])-r.AvgTotalTime, 2)
+		r.StdevProcessServerHello += math.Pow(float64(timingsProcessServerHello[i])-r.AvgProcessServerHello, 2)
+		r.StdevWriteClientHello += math.Pow(float64(timingsWriteClientHello[i])-r.AvgWriteClientHello, 2)
+	}
+	r.StdevTotalTime = math.Sqrt(r.StdevTotalTime / float64(hs))
+	r.StdevProcessServerHello = math.Sqrt(r.StdevProcessServerHello / float64(hs))
+	r.StdevWriteClientHello = math.Sqrt(r.StdevWriteClientHello / float64(hs))
+
+	return r
+}
+
+//print results
+func TLSPrintStatistics(results []TLSClientResultsInfo) {
+	//header
+	fmt.Printf("%-47s | ", "TestName")
+	fmt.Printf("%-20s | ", "AvgClientTotalTime")
+	fmt.Printf("%-20s | ", "StdevClientTotalTime")
+	fmt.Printf("%-20s | ", "AvgWrtCHelloTime")
+	fmt.Printf("%-20s | ", "StdevWrtCHelloTime")
+	fmt.Printf("%-20s | ", "AvgPrSHelloTime")
+	fmt.Printf("%-20s  ", "StdevPrSHelloTime")
+
+	for _, r := range results {
+		//content
+		fmt.Println()
+		fmt.Printf("%23s %23s |", r.KEXName, r.AuthName)
+
+		fmt.Printf(" %-20f |", r.AvgTotalTime)
+		fmt.Printf(" %-20f |", r.StdevTotalTime)
+		fmt.Printf(" %-20f |", r.AvgWriteClientHello)
+		fmt.Printf(" %-20f |", r.StdevWriteClientHello)
+		fmt.Printf(" %-20f |", r.AvgProcessServerHello)
+		fmt.Printf(" %-20f ", r.StdevProcessServerHello)
+	}
+}
+
+// Results files of the PQTLS handshakes. Classic TLS results are saved in the same format as the PQTLS ones.
+func TLSFiles(classic, cachedCert bool) Files {
+	prefix := "csv/pqtls"
+	if classic {
+		prefix = "csv/tls"
+	}
+	if cachedCert {
+		prefix += "-cached-cert"
+	}
+
+	return Files{
+		Client:        prefix + "-client.csv",
+		Server:        prefix + "-server.csv",
+		ClientSizes:   prefix + "-client-sizes.csv",
+		ServerSizes:   prefix + "-server-sizes.csv",
+		ClientFlights: prefix + "-client-flights.csv",
+		ClientSummary: prefix + "-client-summary.csv",
+	}
+}
+
+func TLSInitCSV(files Files) {
+	csvFile, err := os.Create(files.Client)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	csvwriter := csv.NewWriter(csvFile)
+
+	header := []string{"KEXAlgo", "authAlgo", "timingFullProtocol/timingSendAppData", "timingProcessServerHello", "timingWriteClientHello", "cpuUser", "cpuSystem", "allocs", "allocBytes", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
+
+	csvwriter.Write(header)
+	csvwriter.Flush()
+	csvFile.Close()
+
+	csvFile, err = os.Create(files.ClientSizes)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	csvwriter = csv.NewWriter(csvFile)
+
+	header = []string{"KEXAlgo", "authAlgo", "ClientHello", "Certificate", "CertificateVerify", "Finished", "Total", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
+
+	csvwriter.Write(header)
+	csvwriter.Flush()
+	csvFile.Close()
+}
+
+func TLSSaveCSV(files Files, timingsFullProtocol []float64, timingsProcessServerHello []float64, timingsWriteClientHello []float64, name, authName string, levels Levels, hs int, sizes map[string]uint32, resources []Resources) {
+	kexLevel, authLevel, caLevel := levels.strings()
+	keyShare := files.initialKeyShare(name)
+
+	csvFile, err := os.OpenFile(files.Client, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		log.Fatalf("failed opening file: %s", err)
+	}
+
+	csvwriter := csv.NewWriter(csvFile)
+
+	for i := 0; i < hs; i++ {
+		arrayStr := []string{name, authName, fmt.Sprintf("%f", timingsFullProtocol[i]),
+			fmt.Sprintf("%f", timingsProcessServerHello[i]),
+			fmt.Sprintf("%f", timingsWriteClientHello[i])}
+		arrayStr = append(arrayStr, resourcesColumns(resources, i)...)
+		arrayStr = append(arrayStr, kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link)
+
+		if err := csvwriter.Write(arrayStr); err != nil {
+			log.Fatalln("error writing record to file", err)
+		}
+		csvwriter.Flush()
+	}
+	csvFile.Close()
+
+	csvFile, err = os.OpenFile(files.ClientSizes, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		log.Fatalf("failed opening file: %s", err)
+	}
+
+	csvwriter = csv.NewWriter(csvFile)
+
+	totalSizes := sizes["ClientHello"] + sizes["Certificate"] + sizes["CertificateVerify"] + sizes["Finished"]
+
+	arrayStr := []string{name, authName,
+		fmt.Sprintf("%d", sizes["ClientHello"]),		
+		fmt.Sprintf("%d", sizes["Certificate"]),
+		fmt.Sprintf("%d", sizes["CertificateVerify"]),
+		fmt.Sprintf("%d", sizes["Finished"]),
+		fmt.Sprintf("%d", totalSizes),
+		kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link,
+	}
+
+	if err := csvwriter.Write(arrayStr); err != nil {
+		log.Fatalln("error writing record to file", err)
+	}
+	csvwriter.Flush()
+	csvFile.Close()
+}
+
+func TLSInitCSVServer(files Files) {
+	csvFile, err := os.Create(files.Server)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	csvwriter := csv.NewWriter(csvFile)
+
+	header := []string{"KEXAlgo", "authAlgo", "timingFullProtocol", "timingWriteServerHello", "timingWriteCertVerify", "cpuUser", "cpuSystem", "allocs", "allocBytes", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
+
+	csvwriter.Write(header)
+	csvwriter.Flush()
+	csvFile.Close()
+
+	
+	csvFile, err = os.Create(files.ServerSizes)
+	if err != nil {
+		log.Fatalf("failed creating file: %s", err)
+	}
+	csvwriter = csv.NewWriter(csvFile)
+
+	header = []string{"KEXAlgo", "authAlgo", "ServerHello", "EncryptedExtensions", "Certificate", "CertificateRequest", "CertificateVerify", "Finished", "Total", "kexLevel", "authLevel", "caLevel", "handshake", "chain", "keyShare", "link"}
+
+	csvwriter.Write(header)
+	csvwriter.Flush()
+	csvFile.Close()
+}
+
+func TLSSaveCSVServer(files Files, timingsFullProtocol []float64, timingsWriteServerHello []float64, timingsWriteCertVerify []float64, name string, authName string, levels Levels, hs int, sizes map[string]uint32, resources []Resources) {
+	kexLevel, authLevel, caLevel := levels.strings()
+	keyShare := files.initialKeyShare(name)
+
+	csvFile, err := os.OpenFile(files.Server, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		log.Fatalf("failed opening file: %s", err)
+	}
+
+	csvwriter := csv.NewWriter(csvFile)
+
+	for i := 0; i < hs; i++ {
+		arrayStr := []string{name, authName, fmt.Sprintf("%f", timingsFullProtocol[i]),
+			fmt.Sprintf("%f", timingsWriteServerHello[i]),
+			fmt.Sprintf("%f", timingsWriteCertVerify[i])}
+		arrayStr = append(arrayStr, resourcesColumns(resources, i)...)
+		arrayStr = append(arrayStr, kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link)
+
+		if err := csvwriter.Write(arrayStr); err != nil {
+			log.Fatalln("error writing record to file", err)
+		}
+		csvwriter.Flush()
+	}
+	csvFile.Close()
+
+	csvFile, err = os.OpenFile(files.ServerSizes, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+	if err != nil {
+		log.Fatalf("failed opening file: %s", err)
+	}
+
+	csvwriter = csv.NewWriter(csvFile)
+
+	totalSizes := sizes["ServerHello"] + sizes["EncryptedExtensions"] + sizes["Certificate"] + sizes["CertificateRequest"] + sizes["CertificateVerify"] + sizes["Finished"]
+
+	arrayStr := []string{name, authName, 
+		fmt.Sprintf("%d", sizes["ServerHello"]),
+		fmt.Sprintf("%d", sizes["EncryptedExtensions"]),
+		fmt.Sprintf("%d", sizes["Certificate"]),
+		fmt.Sprintf("%d", sizes["CertificateRequest"]),
+		fmt.Sprintf("%d", sizes["CertificateVerify"]),
+		fmt.Sprintf("%d", sizes["Finished"]),
+		fmt.Sprintf("%d", totalSizes),
+		kexLevel, authLevel, caLevel, files.Handshake, files.Chain, keyShare, files.Link,
+	}
+
+	if err := csvwriter.Write(arrayStr); err != nil {
+		log.Fatalln("error writing record to file", err)
+	}
+	csvwriter.Flush()
+	csvFile.Close()
+
+}